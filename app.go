@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"os" // Added for os.UserHomeDir()
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -15,13 +17,37 @@ type App struct {
 	ctx context.Context
 
 	currentDir         string
+	workspaceName      string // active workspace, empty when none
+	workspacePath      string
 	currentFile        string
 	currentFileContent string
+
+	// tabs holds the open documents in order; the active one is currentFile
+	tabs   []*Tab
+	tabsMu sync.Mutex
+
+	// viewStates remembers where the user left off in each file
+	viewStates *viewStateStore
+
+	// stopGeometryWatch stops the window geometry watcher started in domReady
+	stopGeometryWatch context.CancelFunc
+
+	// stopBackups stops the backup scheduler, guarded by schedulerMu;
+	// backupMu serialises backup runs
+	stopBackups context.CancelFunc
+	schedulerMu sync.Mutex
+	backupMu    sync.Mutex
+
+	// tasks caches the checklist items of each note
+	tasks *taskIndex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{
+		viewStates: newViewStateStore(maxViewStates),
+		tasks:      newTaskIndex(),
+	}
 }
 
 // startup is called when the app starts. The context is saved
@@ -34,14 +60,11 @@ func (a *App) startup(ctx context.Context) {
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		// Fallback to home directory
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			fmt.Printf("Error getting user home directory: %v\n", err)
-			a.currentDir = "/" // Fallback to root
-		} else {
-			a.currentDir = homeDir
-		}
+		a.currentDir = homeDirOrRoot()
 	} else {
+		// Remember which workspace was active
+		a.loadActiveWorkspace(config)
+
 		// Use last opened directory if it exists
 		if config.LastOpenedDirectory != "" {
 			// Verify directory still exists
@@ -49,36 +72,47 @@ func (a *App) startup(ctx context.Context) {
 				a.currentDir = config.LastOpenedDirectory
 			} else {
 				// Directory no longer exists, fall back to home
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					a.currentDir = "/"
-				} else {
-					a.currentDir = homeDir
-				}
+				a.currentDir = homeDirOrRoot()
 			}
 		} else {
 			// No last opened directory, use home
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				a.currentDir = "/"
-			} else {
-				a.currentDir = homeDir
-			}
+			a.currentDir = homeDirOrRoot()
 		}
 
-		// Use last opened file if it exists
-		if config.LastOpenedFile != "" {
-			// Verify file still exists
-			if _, err := os.Stat(config.LastOpenedFile); err == nil {
-				a.currentFile = config.LastOpenedFile
-			}
-		}
+		// Reopen saved tabs, activating the last opened file if it still exists
+		a.restoreTabs(config)
+
+		// Take scheduled backups in the background
+		a.startBackupScheduler(config.Backup)
 	}
 
 	// Set initial window title
 	a.UpdateWindowTitleWithCurrentDir()
 }
 
+// homeDirOrRoot returns the user's home directory, or the file system root
+// when it can't be found
+func homeDirOrRoot() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Printf("Error getting user home directory: %v\n", err)
+		return "/"
+	}
+	return homeDir
+}
+
+// shutdown is called when the app is terminating
+func (a *App) shutdown(ctx context.Context) {
+	if a.stopGeometryWatch != nil {
+		a.stopGeometryWatch()
+	}
+	a.schedulerMu.Lock()
+	if a.stopBackups != nil {
+		a.stopBackups()
+	}
+	a.schedulerMu.Unlock()
+}
+
 // Greet returns a greeting for the given name
 func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
@@ -89,9 +123,32 @@ func (a *App) SetWindowTitle(title string) {
 	runtime.WindowSetTitle(a.ctx, title)
 }
 
-// UpdateWindowTitleWithCurrentDir updates the window title to show the current directory
+// emitEvent sends an event to the frontend. It does nothing when the app runs
+// without a window, such as from the command line.
+func (a *App) emitEvent(name string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
+
+// UpdateWindowTitleWithCurrentDir updates the window title to show the active workspace and current directory
 func (a *App) UpdateWindowTitleWithCurrentDir() {
-	if a.currentDir != "" {
+	if a.ctx == nil {
+		return
+	}
+	if a.workspaceName != "" {
+		// Show the workspace name, with the folder relative to it when inside
+		dir := a.currentDir
+		if rel, err := filepath.Rel(a.workspacePath, a.currentDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			dir = rel
+		}
+		if dir == "." || dir == "" {
+			runtime.WindowSetTitle(a.ctx, fmt.Sprintf("Markdowns - %s", a.workspaceName))
+		} else {
+			runtime.WindowSetTitle(a.ctx, fmt.Sprintf("Markdowns - %s - %s", a.workspaceName, dir))
+		}
+	} else if a.currentDir != "" {
 		runtime.WindowSetTitle(a.ctx, fmt.Sprintf("Markdowns - %s", a.currentDir))
 	} else {
 		runtime.WindowSetTitle(a.ctx, "Markdowns")
@@ -154,25 +211,5 @@ func (a *App) ResolveImagePath(relativePath string) (string, error) {
 		baseDir = a.currentDir
 	}
 
-	// Decode URL-encoded path
-	decodedPath, err := url.PathUnescape(relativePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode path: %w", err)
-	}
-
-	// If path is already absolute, return as is
-	if filepath.IsAbs(decodedPath) {
-		return decodedPath, nil
-	}
-
-	// Resolve relative path to absolute
-	absolutePath := filepath.Join(baseDir, decodedPath)
-	absolutePath = filepath.Clean(absolutePath)
-
-	// Verify file exists
-	if _, err := os.Stat(absolutePath); err != nil {
-		return "", fmt.Errorf("image file not found: %w", err)
-	}
-
-	return absolutePath, nil
+	return resolveImagePath(baseDir, relativePath)
 }
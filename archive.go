@@ -0,0 +1,346 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// workspaceZipProgressEvent is emitted while a workspace archive is written or extracted
+const workspaceZipProgressEvent = "workspace-zip:progress"
+
+// progressInterval limits how often progress events are sent
+const progressInterval = 100 * time.Millisecond
+
+// WorkspaceZipProgress reports how far an archive export or import has got
+type WorkspaceZipProgress struct {
+	Operation string `json:"operation"` // "export", "import" or "restore"
+	Current   int    `json:"current"`
+	Total     int    `json:"total"`
+	Path      string `json:"path"` // the file just processed, relative to the workspace
+	Done      bool   `json:"done"`
+}
+
+// WorkspaceZipResult summarises an archive export or import
+type WorkspaceZipResult struct {
+	Path    string   `json:"path"` // the archive for exports, the target directory for imports
+	Files   int      `json:"files"`
+	Bytes   int64    `json:"bytes"`
+	Skipped []string `json:"skipped"` // archive entries left out of an import or restore
+
+	extracted []string // absolute paths of the files an import or restore wrote
+}
+
+// progressReporter emits progress events, throttled so large workspaces don't flood the frontend
+type progressReporter struct {
+	app       *App
+	operation string
+	total     int
+	lastSent  time.Time
+}
+
+// report sends progress after current files unless one was sent very recently.
+// A nil reporter does nothing.
+func (p *progressReporter) report(current int, rel string) {
+	if p == nil {
+		return
+	}
+	done := current == p.total
+	if !done && time.Since(p.lastSent) < progressInterval {
+		return
+	}
+	p.lastSent = time.Now()
+	p.app.emitEvent(workspaceZipProgressEvent, WorkspaceZipProgress{
+		Operation: p.operation,
+		Current:   current,
+		Total:     p.total,
+		Path:      filepath.ToSlash(rel),
+		Done:      done,
+	})
+}
+
+// ExportWorkspaceZip writes every file in the workspace that the ignore rules
+// keep to a zip archive at dest, preserving modification times
+func (a *App) ExportWorkspaceZip(dest string) (WorkspaceZipResult, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return WorkspaceZipResult{Path: dest}, fmt.Errorf("no workspace is open")
+	}
+
+	progress := &progressReporter{app: a, operation: "export"}
+	return writeWorkspaceZip(root, dest, nil, "", progress)
+}
+
+// writeWorkspaceZip archives the files under root that the ignore rules keep,
+// skipping the directories in skipDirs, and stores comment in the archive.
+// progress may be nil.
+func writeWorkspaceZip(root string, dest string, skipDirs []string, comment string, progress *progressReporter) (WorkspaceZipResult, error) {
+	result := WorkspaceZipResult{Path: dest}
+
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", dest, err)
+	}
+
+	// Collect first so progress has a total
+	type archiveFile struct {
+		path string
+		rel  string
+		info fs.FileInfo
+	}
+	var files []archiveFile
+	var dirs []archiveFile
+	err = walkWorkspace(root, skipDirs, func(path string, rel string, d fs.DirEntry) error {
+		if abs, _ := filepath.Abs(path); abs == absDest {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, archiveFile{path, rel, info})
+		} else if info.Mode().IsRegular() {
+			files = append(files, archiveFile{path, rel, info})
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to read workspace %s: %w", root, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return result, fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+
+	// Write to a temporary file so a failed export doesn't leave a partial archive
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return result, fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer os.Remove(tmp)
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	if comment != "" {
+		if err := zw.SetComment(comment); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+	}
+
+	// Directory entries keep empty folders and their times
+	for _, dir := range dirs {
+		header, err := zip.FileInfoHeader(dir.info)
+		if err != nil {
+			return result, fmt.Errorf("failed to archive %s: %w", dir.rel, err)
+		}
+		header.Name = filepath.ToSlash(dir.rel) + "/"
+		if _, err := zw.CreateHeader(header); err != nil {
+			return result, fmt.Errorf("failed to archive %s: %w", dir.rel, err)
+		}
+	}
+
+	if progress != nil {
+		progress.total = len(files)
+	}
+	for i, file := range files {
+		written, err := addFileToZip(zw, file.path, filepath.ToSlash(file.rel), file.info)
+		if err != nil {
+			return result, err
+		}
+		result.Files++
+		result.Bytes += written
+		result.extracted = append(result.extracted, dest)
+		progress.report(i+1, file.rel)
+	}
+	if len(files) == 0 {
+		progress.report(0, "")
+	}
+
+	if err := zw.Close(); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+
+	return result, nil
+}
+
+// addFileToZip copies a file into the archive under name, keeping its modification time
+func addFileToZip(zw *zip.Writer, path string, name string, info fs.FileInfo) (int64, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer in.Close()
+
+	written, err := io.Copy(w, in)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return written, nil
+}
+
+// ImportWorkspaceZip extracts a workspace archive into targetDir, restoring
+// modification times. Entries that would land outside targetDir are rejected,
+// and nothing is extracted if any file would overwrite an existing one.
+// Entries targetDir's ignore rules exclude are skipped, as on export.
+func (a *App) ImportWorkspaceZip(src string, targetDir string) (WorkspaceZipResult, error) {
+	if targetDir == "" {
+		return WorkspaceZipResult{Path: targetDir}, fmt.Errorf("target directory cannot be empty")
+	}
+
+	rules := loadIgnoreRules(targetDir)
+	progress := &progressReporter{app: a, operation: "import"}
+	return extractWorkspaceZip(src, targetDir, false, rules.excludes, progress)
+}
+
+// extractWorkspaceZip extracts src into targetDir. Existing files are replaced
+// when overwrite is set; otherwise they stop the extraction before it starts.
+// Entries skip reports true for are left out and listed in the result's
+// Skipped. skip and progress may be nil.
+func extractWorkspaceZip(src string, targetDir string, overwrite bool, skip func(rel string, isDir bool) bool, progress *progressReporter) (WorkspaceZipResult, error) {
+	result := WorkspaceZipResult{Path: targetDir, Skipped: []string{}}
+
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return result, fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer zr.Close()
+
+	// Validate every entry before touching the file system
+	type extractEntry struct {
+		file *zip.File
+		rel  string
+	}
+	var files []extractEntry
+	var dirs []extractEntry
+	for _, f := range zr.File {
+		rel, err := safeArchivePath(f.Name)
+		if err != nil {
+			return result, fmt.Errorf("refusing to extract %s: %w", src, err)
+		}
+		if f.Mode()&fs.ModeSymlink != 0 {
+			return result, fmt.Errorf("refusing to extract %s: %s is a symbolic link", src, f.Name)
+		}
+
+		dest := filepath.Join(targetDir, filepath.FromSlash(rel))
+		if skip != nil && skip(rel, f.FileInfo().IsDir()) {
+			result.Skipped = append(result.Skipped, rel)
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			dirs = append(dirs, extractEntry{f, rel})
+			continue
+		}
+		if _, err := os.Stat(dest); err == nil && !overwrite {
+			return result, fmt.Errorf("file %s already exists in %s", rel, targetDir)
+		}
+		files = append(files, extractEntry{f, rel})
+	}
+
+	if progress != nil {
+		progress.total = len(files)
+	}
+	for i, entry := range files {
+		dest := filepath.Join(targetDir, filepath.FromSlash(entry.rel))
+		written, err := extractZipFile(entry.file, dest, overwrite)
+		if err != nil {
+			return result, err
+		}
+		result.Files++
+		result.Bytes += written
+		result.extracted = append(result.extracted, dest)
+		progress.report(i+1, entry.rel)
+	}
+	if len(files) == 0 {
+		progress.report(0, "")
+	}
+
+	// Directory times last, since creating files inside them updates them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dest := filepath.Join(targetDir, filepath.FromSlash(dirs[i].rel))
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return result, fmt.Errorf("failed to create directory %s: %w", dest, err)
+		}
+		modified := dirs[i].file.Modified
+		if !modified.IsZero() {
+			os.Chtimes(dest, modified, modified)
+		}
+	}
+
+	return result, nil
+}
+
+// extractZipFile writes one archive entry to dest and restores its modification
+// time. An existing file is only replaced when overwrite is set, and then
+// atomically so an interrupted restore never leaves it half written.
+func extractZipFile(f *zip.File, dest string, overwrite bool) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	perm := f.Mode().Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	target := dest
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		target = dest + ".tmp"
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		defer os.Remove(target)
+	}
+	out, err := os.OpenFile(target, flags, perm)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+
+	written, err := io.Copy(out, rc)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if overwrite {
+		if err := os.Rename(target, dest); err != nil {
+			return 0, fmt.Errorf("failed to replace %s: %w", dest, err)
+		}
+	}
+
+	if !f.Modified.IsZero() {
+		if err := os.Chtimes(dest, f.Modified, f.Modified); err != nil {
+			return 0, fmt.Errorf("failed to set modification time of %s: %w", dest, err)
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestImportWorkspaceZipSkipsIgnoredEntries(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "workspace.zip")
+	file, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(file)
+	for _, name := range []string{"notes/a.md", "drafts/b.md", "notes/scratch.tmp", ".hidden"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	target := t.TempDir()
+	writeTestFile(t, filepath.Join(target, ignoreFileName), "drafts/\n*.tmp\n")
+
+	result, err := NewApp().ImportWorkspaceZip(src, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 1 {
+		t.Errorf("imported %d files, want only notes/a.md", result.Files)
+	}
+	slices.Sort(result.Skipped)
+	if want := []string{".hidden", "drafts/b.md", "notes/scratch.tmp"}; !slices.Equal(result.Skipped, want) {
+		t.Errorf("skipped %q, want %q", result.Skipped, want)
+	}
+	if _, err := os.Stat(filepath.Join(target, "notes", "a.md")); err != nil {
+		t.Error(err)
+	}
+	for _, name := range []string{"drafts", filepath.Join("notes", "scratch.tmp"), ".hidden"} {
+		if _, err := os.Stat(filepath.Join(target, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was imported despite the ignore rules", name)
+		}
+	}
+}
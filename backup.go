@@ -0,0 +1,453 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// backupTimeLayout is the timestamp in backup file names
+	backupTimeLayout = "20060102-150405"
+
+	// backupCreatedEvent is emitted after a scheduled backup is written
+	backupCreatedEvent = "backup:created"
+
+	// backupFingerprintPrefix marks the workspace fingerprint stored in a backup's zip comment
+	backupFingerprintPrefix = "markdowns-fingerprint:"
+)
+
+// BackupSettings controls scheduled workspace backups
+type BackupSettings struct {
+	Enabled         bool   `json:"enabled"`
+	Directory       string `json:"directory"`       // defaults to "backups" in the data directory
+	IntervalMinutes int    `json:"intervalMinutes"` // time between scheduled backups
+	KeepDaily       int    `json:"keepDaily"`       // newest backup of each of the last N days
+	KeepWeekly      int    `json:"keepWeekly"`      // newest backup of each of the last N weeks
+	KeepMonthly     int    `json:"keepMonthly"`     // newest backup of each of the last N months
+}
+
+// BackupInfo describes a backup archive
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Workspace string    `json:"workspace"`
+	Created   time.Time `json:"created"`
+	Size      int64     `json:"size"`
+}
+
+// defaultBackupSettings returns the settings used until the user changes them
+func defaultBackupSettings() BackupSettings {
+	return BackupSettings{
+		Enabled:         false,
+		IntervalMinutes: 60,
+		KeepDaily:       7,
+		KeepWeekly:      4,
+		KeepMonthly:     12,
+	}
+}
+
+// GetBackupSettings returns the backup settings
+func (a *App) GetBackupSettings() (BackupSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return BackupSettings{}, err
+	}
+	return config.Backup, nil
+}
+
+// SetBackupSettings saves the backup settings and restarts the scheduler with them
+func (a *App) SetBackupSettings(settings BackupSettings) error {
+	if settings.IntervalMinutes < 1 {
+		return fmt.Errorf("backup interval must be at least 1 minute")
+	}
+	if settings.KeepDaily < 0 || settings.KeepWeekly < 0 || settings.KeepMonthly < 0 {
+		return fmt.Errorf("backup retention counts cannot be negative")
+	}
+
+	err := updateConfig(func(config *Config) error {
+		config.Backup = settings
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.startBackupScheduler(settings)
+	return nil
+}
+
+// restartBackupScheduler restarts the backup loop for the current workspace
+func (a *App) restartBackupScheduler() {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Printf("Warning: Could not load config: %v\n", err)
+		return
+	}
+	a.startBackupScheduler(config.Backup)
+}
+
+// startBackupScheduler (re)starts the background backup loop
+func (a *App) startBackupScheduler(settings BackupSettings) {
+	a.schedulerMu.Lock()
+	defer a.schedulerMu.Unlock()
+
+	if a.stopBackups != nil {
+		a.stopBackups()
+		a.stopBackups = nil
+	}
+	if !settings.Enabled || settings.IntervalMinutes < 1 {
+		return
+	}
+
+	// The goroutine backs up the folder that is the workspace root now, so it
+	// never reads workspace state another goroutine is changing. Switching
+	// workspace restarts the scheduler.
+	root := a.workspaceRoot()
+	if root == "" {
+		return
+	}
+
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.stopBackups = cancel
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(settings.IntervalMinutes) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := a.runBackup(root, settings, false)
+				if err != nil {
+					fmt.Printf("Warning: Scheduled backup failed: %v\n", err)
+					continue
+				}
+				if info != nil {
+					a.emitEvent(backupCreatedEvent, *info)
+				}
+			}
+		}
+	}()
+}
+
+// BackupNow backs up the workspace immediately, even if nothing changed
+func (a *App) BackupNow() (BackupInfo, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return BackupInfo{}, err
+	}
+
+	info, err := a.runBackup(a.workspaceRoot(), config.Backup, true)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	return *info, nil
+}
+
+// runBackup writes a backup of the workspace at root and prunes old ones.
+// Unless force is set, it returns nil without writing when the workspace
+// hasn't changed since the newest backup.
+func (a *App) runBackup(root string, settings BackupSettings, force bool) (*BackupInfo, error) {
+	a.backupMu.Lock()
+	defer a.backupMu.Unlock()
+
+	return runBackupLocked(root, settings, force, "")
+}
+
+// runBackupLocked is runBackup for callers holding backupMu. Pruning never
+// deletes the backup at keepPath, when it isn't "".
+func runBackupLocked(root string, settings BackupSettings, force bool, keepPath string) (*BackupInfo, error) {
+	dir, err := workspaceBackupDir(root, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := workspaceFingerprint(root, []string{dir})
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := listBackupsIn(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !force && len(backups) > 0 && readBackupFingerprint(backups[0].Path) == fingerprint {
+		return nil, nil
+	}
+
+	name := filepath.Base(dir)
+	created := time.Now()
+	dest := filepath.Join(dir, fmt.Sprintf("%s-%s.zip", name, created.Format(backupTimeLayout)))
+	// Two backups in the same second would share a name
+	for i := 1; fileExists(dest); i++ {
+		dest = filepath.Join(dir, fmt.Sprintf("%s-%s-%d.zip", name, created.Format(backupTimeLayout), i))
+	}
+
+	result, err := writeWorkspaceZip(root, dest, []string{dir}, backupFingerprintPrefix+fingerprint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pruneBackups(dir, settings, keepPath); err != nil {
+		fmt.Printf("Warning: Could not prune old backups: %v\n", err)
+	}
+
+	info := BackupInfo{
+		Name:      filepath.Base(dest),
+		Path:      dest,
+		Workspace: name,
+		Created:   created,
+		Size:      result.Bytes,
+	}
+	if stat, err := os.Stat(dest); err == nil {
+		info.Size = stat.Size()
+	}
+	return &info, nil
+}
+
+// workspaceBackupDir returns the folder holding the backups of the workspace
+// at root. It is named after the workspace folder with a hash of its full
+// path, so workspaces whose names clean up the same, such as "my notes" and
+// "my-notes", never share a folder or prune each other's backups.
+func workspaceBackupDir(root string, settings BackupSettings) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("no workspace is open")
+	}
+
+	base := settings.Directory
+	if base == "" {
+		dataDir, err := GetDataDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(dataDir, "backups")
+	}
+
+	hash := sha256.Sum256([]byte(filepath.Clean(root)))
+	name := fmt.Sprintf("%s-%s", sanitizePackageName(filepath.Base(root)), hex.EncodeToString(hash[:4]))
+	return filepath.Join(base, name), nil
+}
+
+// workspaceFingerprint hashes the path, size and modification time of every
+// file the ignore rules keep, so unchanged workspaces can be detected cheaply
+func workspaceFingerprint(root string, skipDirs []string) (string, error) {
+	hash := sha256.New()
+	err := walkWorkspace(root, skipDirs, func(path string, rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read workspace %s: %w", root, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readBackupFingerprint returns the fingerprint stored in a backup, or "" if it has none
+func readBackupFingerprint(path string) string {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return ""
+	}
+	defer zr.Close()
+	return strings.TrimPrefix(zr.Comment, backupFingerprintPrefix)
+}
+
+// ListBackups returns the active workspace's backups, newest first
+func (a *App) ListBackups() ([]BackupInfo, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := workspaceBackupDir(a.workspaceRoot(), config.Backup)
+	if err != nil {
+		return nil, err
+	}
+	return listBackupsIn(dir)
+}
+
+// listBackupsIn returns the backups in dir, newest first
+func listBackupsIn(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BackupInfo{}, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory %s: %w", dir, err)
+	}
+
+	workspace := filepath.Base(dir)
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".zip") || !strings.HasPrefix(name, workspace+"-") {
+			continue
+		}
+		created, ok := parseBackupTime(strings.TrimSuffix(strings.TrimPrefix(name, workspace+"-"), ".zip"))
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{
+			Name:      name,
+			Path:      filepath.Join(dir, name),
+			Workspace: workspace,
+			Created:   created,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Created.Equal(backups[j].Created) {
+			return backups[i].Name > backups[j].Name
+		}
+		return backups[i].Created.After(backups[j].Created)
+	})
+	return backups, nil
+}
+
+// parseBackupTime reads the timestamp from a backup name, ignoring a "-N" collision suffix
+func parseBackupTime(stamp string) (time.Time, bool) {
+	if len(stamp) < len(backupTimeLayout) {
+		return time.Time{}, false
+	}
+	created, err := time.ParseInLocation(backupTimeLayout, stamp[:len(backupTimeLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
+
+// pruneBackups deletes the backups in dir that the retention settings don't
+// keep. Each rule keeps the newest backup of each of its last N periods; the
+// newest backup overall is always kept, as is the one at keepPath when it
+// isn't "". With every count at zero nothing is deleted.
+func pruneBackups(dir string, settings BackupSettings, keepPath string) error {
+	if settings.KeepDaily == 0 && settings.KeepWeekly == 0 && settings.KeepMonthly == 0 {
+		return nil
+	}
+
+	backups, err := listBackupsIn(dir)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]bool{keepPath: true}
+	if len(backups) > 0 {
+		keep[backups[0].Path] = true
+	}
+
+	rules := []struct {
+		count  int
+		period func(time.Time) string
+	}{
+		{settings.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{settings.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{settings.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		seen := map[string]bool{}
+		for _, backup := range backups {
+			if len(seen) >= rule.count {
+				break
+			}
+			period := rule.period(backup.Created)
+			if seen[period] {
+				continue
+			}
+			seen[period] = true
+			keep[backup.Path] = true
+		}
+	}
+
+	for _, backup := range backups {
+		if keep[backup.Path] {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
+		}
+	}
+	return nil
+}
+
+// RestoreBackup restores the named backup over the active workspace. Files
+// in the backup replace the current ones; files added since are left alone,
+// and so are notes with unsaved edits in a tab, which are listed as skipped.
+// The current state is backed up first so the restore can itself be undone.
+func (a *App) RestoreBackup(name string) (WorkspaceZipResult, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return WorkspaceZipResult{}, err
+	}
+
+	root := a.workspaceRoot()
+	if root == "" {
+		return WorkspaceZipResult{}, fmt.Errorf("no workspace is open")
+	}
+
+	dir, err := workspaceBackupDir(root, config.Backup)
+	if err != nil {
+		return WorkspaceZipResult{}, err
+	}
+	if name != filepath.Base(name) {
+		return WorkspaceZipResult{}, fmt.Errorf("invalid backup name %s", name)
+	}
+	path := filepath.Join(dir, name)
+
+	// Hold the lock throughout so a scheduled backup can't prune the archive
+	// between the safety backup and the restore
+	a.backupMu.Lock()
+	defer a.backupMu.Unlock()
+
+	if !fileExists(path) {
+		return WorkspaceZipResult{}, fmt.Errorf("backup %s not found", name)
+	}
+	if _, err := runBackupLocked(root, config.Backup, false, path); err != nil {
+		return WorkspaceZipResult{}, fmt.Errorf("failed to back up the workspace before restoring: %w", err)
+	}
+
+	// Notes with unsaved edits in a tab are left out so the edits aren't lost
+	unsaved := func(rel string, isDir bool) bool {
+		return !isDir && a.hasUnsavedEdits(filepath.Join(root, filepath.FromSlash(rel)))
+	}
+	progress := &progressReporter{app: a, operation: "restore"}
+	result, err := extractWorkspaceZip(path, root, true, unsaved, progress)
+	// Reload whatever was restored, even if the restore stopped part way
+	a.reloadTabs(result.extracted)
+	return result, err
+}
+
+// fileExists reports whether something exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreBackupLeavesUnsavedTabsAlone(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	clean := filepath.Join(root, "clean.md")
+	edited := filepath.Join(root, "edited.md")
+	writeTestFile(t, clean, "backed up\n")
+	writeTestFile(t, edited, "backed up\n")
+
+	app := NewApp()
+	app.workspacePath = root
+	backup, err := app.BackupNow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, clean, "changed\n")
+	writeTestFile(t, edited, "changed\n")
+	for _, path := range []string{clean, edited} {
+		if _, err := app.OpenTab(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := app.UpdateTabContent(edited, "changed, unsaved\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := app.RestoreBackup(backup.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "edited.md" {
+		t.Errorf("skipped %q, want only the note with unsaved edits", result.Skipped)
+	}
+	for path, want := range map[string]string{clean: "backed up\n", edited: "changed\n"} {
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("%s reads %q, want %q", path, data, want)
+		}
+	}
+
+	for _, tab := range app.ListTabs() {
+		switch tab.Path {
+		case clean:
+			if tab.Dirty || tab.ContentHash != hashContent("backed up\n") {
+				t.Errorf("the restored tab %+v does not match the restored note", tab)
+			}
+		case edited:
+			if !tab.Dirty {
+				t.Error("the skipped tab lost its unsaved edits")
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// cliCommand is a subcommand run from the command line instead of the GUI
+type cliCommand struct {
+	Name    string
+	Usage   string
+	Summary string
+	Run     func(app *App, args []string) int
+}
+
+const exportPDFUsage = "export-pdf [-o output.pdf] [-page-size A4|Letter|Legal] [-title title] [-no-toc] <note or folder>"
+
+const lintUsage = "lint [-quiet] [-json] <note or folder>..."
+
+const tocUsage = "toc [-check] [-min-level n] [-max-level n] [-numbered] [-anchors github|gitlab|none] <note or folder>..."
+
+// cliCommands lists the available subcommands
+var cliCommands = []cliCommand{
+	{
+		Name:    "export-pdf",
+		Usage:   exportPDFUsage,
+		Summary: "Export a note or a folder of notes to PDF",
+		Run:     runExportPDFCommand,
+	},
+	{
+		Name:    "toc",
+		Usage:   tocUsage,
+		Summary: "Refresh or check the tables of contents between toc markers",
+		Run:     runTOCCommand,
+	},
+	{
+		Name:    "lint",
+		Usage:   lintUsage,
+		Summary: "Check notes against the lint rules",
+		Run:     runLintCommand,
+	},
+}
+
+// runCLI runs the subcommand named by args[0], if there is one.
+// It reports whether a subcommand was run and the exit code to use.
+func runCLI(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printCLIUsage(os.Stdout)
+		return true, 0
+	}
+
+	for _, command := range cliCommands {
+		if command.Name == args[0] {
+			return true, command.Run(NewApp(), args[1:])
+		}
+	}
+
+	// Anything else is left to the GUI, which may receive platform arguments
+	return false, 0
+}
+
+// printCLIUsage lists the subcommands
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: markdowns [command] [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Without a command the desktop app is started.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	for _, command := range cliCommands {
+		fmt.Fprintf(w, "  %-14s %s\n", command.Name, command.Summary)
+	}
+}
+
+// newCommandFlags returns a flag set that prints the command's usage on error
+func newCommandFlags(name string, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: markdowns %s\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runExportPDFCommand implements "markdowns export-pdf"
+func runExportPDFCommand(app *App, args []string) int {
+	flags := newCommandFlags("export-pdf", exportPDFUsage)
+	var options PDFExportOptions
+	flags.StringVar(&options.OutputPath, "o", "", "output file (default: next to the input with a .pdf extension)")
+	flags.StringVar(&options.PageSize, "page-size", "A4", "page size")
+	flags.StringVar(&options.Title, "title", "", "title above the table of contents")
+	flags.BoolVar(&options.NoTOC, "no-toc", false, "skip the table of contents")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	outputPath, err := app.ExportPDF(flags.Arg(0), options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(outputPath)
+	return 0
+}
+
+// runTOCCommand implements "markdowns toc". Only notes that already have toc
+// markers are touched. With -check nothing is written and the exit code is 1
+// when any table of contents is out of date, for use in CI.
+func runTOCCommand(app *App, args []string) int {
+	flags := newCommandFlags("toc", tocUsage)
+	var options TOCOptions
+	check := flags.Bool("check", false, "report out of date tables of contents instead of updating them")
+	flags.IntVar(&options.MinLevel, "min-level", 1, "shallowest heading level to include")
+	flags.IntVar(&options.MaxLevel, "max-level", 6, "deepest heading level to include")
+	flags.BoolVar(&options.Numbered, "numbered", false, "number the entries instead of using bullets")
+	flags.StringVar(&options.AnchorStyle, "anchors", anchorStyleGitHub, "anchor style")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	if err := validateTOCOptions(options); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	var files []string
+	for _, arg := range flags.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		// A folder argument is treated as a workspace root
+		notes, err := collectMarkdownFiles(arg, noteSkipDirs(arg))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		files = append(files, notes...)
+	}
+
+	stale := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read file %s: %v\n", file, err)
+			return 1
+		}
+		updated, _, found := updateTOCContent(string(data), options, false)
+		if !found || updated == string(data) {
+			continue
+		}
+
+		stale++
+		if *check {
+			fmt.Printf("%s: table of contents is out of date\n", file)
+			continue
+		}
+		if _, err := app.UpdateTOC(file, options); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("%s: table of contents updated\n", file)
+	}
+
+	if *check && stale > 0 {
+		fmt.Fprintf(os.Stderr, "%d table(s) of contents out of date; run \"markdowns toc\" to update them\n", stale)
+		return 1
+	}
+	return 0
+}
+
+// runLintCommand implements "markdowns lint". Each note uses the nearest lint
+// config file in its folder or above. The exit code is 1 when any issue
+// is found, or with -quiet when any error is, for use in CI.
+func runLintCommand(app *App, args []string) int {
+	flags := newCommandFlags("lint", lintUsage)
+	quiet := flags.Bool("quiet", false, "report errors only and ignore warnings")
+	asJSON := flags.Bool("json", false, "print the issues as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	issues := []LintIssue{}
+	configs := newLintConfigCache("")
+	for _, arg := range flags.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+
+		// A folder argument is treated as a workspace root, ignore rules included
+		files := []string{arg}
+		if info.IsDir() {
+			files = nil
+			err := walkNotes(arg, nil, func(path string, rel string, d fs.DirEntry) error {
+				if !d.IsDir() && isMarkdownFile(d.Name()) {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to read directory %s: %v\n", arg, err)
+				return 2
+			}
+		}
+
+		for _, file := range files {
+			config, err := configs.configFor(filepath.Dir(file))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 2
+			}
+			fileIssues, err := lintFile(file, config)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 2
+			}
+			for _, issue := range fileIssues {
+				if *quiet && issue.Severity != lintSeverityError {
+					continue
+				}
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(issues)
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%s:%d:%d: %s %s: %s\n", issue.Path, issue.Line, issue.Column, issue.Severity, issue.Rule, issue.Message)
+		}
+	}
+
+	if len(issues) > 0 {
+		if !*asJSON {
+			fmt.Fprintf(os.Stderr, "%d issue(s) found\n", len(issues))
+		}
+		return 1
+	}
+	return 0
+}
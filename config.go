@@ -5,18 +5,69 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// configMu serialises changes to the config file. The window, tabs, recent
+// files, workspaces and settings all read, modify and write the same file,
+// some from background goroutines, and would otherwise lose each other's updates.
+var configMu sync.Mutex
+
 // Config represents the application configuration
 type Config struct {
 	LastOpenedFile      string            `json:"lastOpenedFile"`
 	LastOpenedDirectory string            `json:"lastOpenedDirectory"`
-	RecentFiles         []string          `json:"recentFiles"`
+	RecentFiles         []RecentFile      `json:"recentFiles"`
+	MaxRecentFiles      int               `json:"maxRecentFiles"`
+	OpenTabs            []string          `json:"openTabs"`
 	WindowWidth         int               `json:"windowWidth"`
 	WindowHeight        int               `json:"windowHeight"`
+	WindowX             int               `json:"windowX"` // relative to the screen, may be negative
+	WindowY             int               `json:"windowY"`
+	WindowPositionSaved bool              `json:"windowPositionSaved"` // false centers the window
+	WindowMaximized     bool              `json:"windowMaximized"`
 	Theme               string            `json:"theme"` // "light" or "dark"
 	ShowHiddenFiles     bool              `json:"showHiddenFiles"`
 	CustomSettings      map[string]string `json:"customSettings"`
+	Workspaces          []Workspace       `json:"workspaces"`
+	ActiveWorkspace     string            `json:"activeWorkspace"`
+	// DefaultSession keeps the session from outside any workspace while one is active
+	DefaultSession Workspace         `json:"defaultSession"`
+	Backup         BackupSettings    `json:"backup"`
+	Git            GitSettings       `json:"git"`
+	DailyNotes     DailyNoteSettings `json:"dailyNotes"`
+	FileNames      FileNameSettings  `json:"fileNames"`
+}
+
+// RecentFile is an entry in the recent files list
+type RecentFile struct {
+	Path       string    `json:"path"`
+	LastOpened time.Time `json:"lastOpened"`
+	OpenCount  int       `json:"openCount"`
+	Pinned     bool      `json:"pinned"`
+}
+
+// UnmarshalJSON also accepts the plain path strings written by older versions
+func (r *RecentFile) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*r = RecentFile{Path: path}
+		return nil
+	}
+
+	// Alias drops this method so the default decoding is used
+	type recentFile RecentFile
+	var entry recentFile
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	*r = RecentFile(entry)
+	return nil
 }
 
 // DefaultConfig returns a new Config with default values
@@ -24,23 +75,38 @@ func DefaultConfig() *Config {
 	return &Config{
 		LastOpenedFile:      "",
 		LastOpenedDirectory: "",
-		RecentFiles:         []string{},
+		RecentFiles:         []RecentFile{},
+		MaxRecentFiles:      10,
+		OpenTabs:            []string{},
 		WindowWidth:         1024,
 		WindowHeight:        768,
+		WindowX:             0,
+		WindowY:             0,
+		WindowPositionSaved: false,
+		WindowMaximized:     false,
 		Theme:               "light",
 		ShowHiddenFiles:     false,
 		CustomSettings:      make(map[string]string),
+		Workspaces:          []Workspace{},
+		ActiveWorkspace:     "",
+		Backup:              defaultBackupSettings(),
+		Git:                 GitSettings{CommitMessage: defaultCommitMessage},
+		DailyNotes:          defaultDailyNoteSettings(),
+		FileNames:           defaultFileNameSettings(),
 	}
 }
 
-// GetConfigPath returns the path to the config file
+// GetConfigPath returns the path to the config file.
+// See GetConfigDir for how the location is resolved.
 func GetConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	// Move a pre-XDG ~/.markdowns config into place before first use
+	migrateLegacyConfig()
+
+	configDir, err := GetConfigDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+		return "", err
 	}
 
-	configDir := filepath.Join(homeDir, ".markdowns")
 	configPath := filepath.Join(configDir, "config.json")
 
 	return configPath, nil
@@ -63,16 +129,43 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Start from defaults so fields missing in older config files keep sensible values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-// SaveConfig saves the configuration to disk
+// SaveConfig saves the configuration to disk, replacing it entirely.
+// Use updateConfig to change part of it.
 func SaveConfig(config *Config) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	return writeConfig(config)
+}
+
+// updateConfig loads the configuration, applies change and saves the result,
+// holding configMu so no other update can slip in between. Nothing is saved
+// when loading fails or change returns an error.
+func updateConfig(change func(config *Config) error) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if err := change(config); err != nil {
+		return err
+	}
+	return writeConfig(config)
+}
+
+// writeConfig writes config to disk. The caller must hold configMu.
+func writeConfig(config *Config) error {
 	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
@@ -90,8 +183,14 @@ func SaveConfig(config *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	// Write config file
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// Write to a temporary file and rename it over the config so readers
+	// never see a half-written file
+	tempPath := configPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tempPath, configPath); err != nil {
+		os.Remove(tempPath)
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -117,11 +216,13 @@ func (a *App) UpdateConfig(configJSON string) error {
 
 // UpdateConfigField updates a single field in the configuration
 func (a *App) UpdateConfigField(field string, value string) error {
-	config, err := LoadConfig()
-	if err != nil {
-		return err
-	}
+	return updateConfig(func(config *Config) error {
+		return setConfigField(config, field, value)
+	})
+}
 
+// setConfigField sets the field named by its JSON key to value
+func setConfigField(config *Config, field string, value string) error {
 	switch field {
 	case "lastOpenedFile":
 		config.LastOpenedFile = value
@@ -131,23 +232,27 @@ func (a *App) UpdateConfigField(field string, value string) error {
 		config.Theme = value
 	case "showHiddenFiles":
 		config.ShowHiddenFiles = value == "true"
+	case "maxRecentFiles":
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("invalid maxRecentFiles value %q", value)
+		}
+		config.MaxRecentFiles = limit
+		config.RecentFiles = capRecentFiles(config.RecentFiles, limit)
 	default:
 		// Store in custom settings if not a known field
 		config.CustomSettings[field] = value
 	}
 
-	return SaveConfig(config)
+	return nil
 }
 
 // SetShowHiddenFiles sets the showHiddenFiles config option
 func (a *App) SetShowHiddenFiles(show bool) error {
-	config, err := LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	config.ShowHiddenFiles = show
-	return SaveConfig(config)
+	return updateConfig(func(config *Config) error {
+		config.ShowHiddenFiles = show
+		return nil
+	})
 }
 
 // GetShowHiddenFiles returns the showHiddenFiles config option
@@ -160,38 +265,142 @@ func (a *App) GetShowHiddenFiles() (bool, error) {
 	return config.ShowHiddenFiles, nil
 }
 
-// AddRecentFile adds a file to the recent files list
+// AddRecentFile adds a file to the recent files list, or bumps it if already there
 func (a *App) AddRecentFile(filePath string) error {
+	return updateConfig(func(config *Config) error {
+		entry := RecentFile{Path: filePath}
+
+		// Remove if already exists (to avoid duplicates), keeping its history
+		for i, f := range config.RecentFiles {
+			if f.Path == filePath {
+				entry = f
+				config.RecentFiles = append(config.RecentFiles[:i], config.RecentFiles[i+1:]...)
+				break
+			}
+		}
+
+		entry.LastOpened = time.Now()
+		entry.OpenCount++
+
+		// Add to beginning of list
+		config.RecentFiles = append([]RecentFile{entry}, config.RecentFiles...)
+		config.RecentFiles = capRecentFiles(config.RecentFiles, config.MaxRecentFiles)
+		return nil
+	})
+}
+
+// GetRecentFiles returns the recent files, pinned first and then most recently opened.
+// Files that no longer exist are pruned from the list.
+func (a *App) GetRecentFiles() ([]RecentFile, error) {
 	config, err := LoadConfig()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	existing := existingRecentFiles(config.RecentFiles)
+	if len(existing) != len(config.RecentFiles) {
+		err := updateConfig(func(config *Config) error {
+			config.RecentFiles = existingRecentFiles(config.RecentFiles)
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Warning: Could not save config: %v\n", err)
+		}
 	}
 
-	// Remove if already exists (to avoid duplicates)
-	for i, f := range config.RecentFiles {
-		if f == filePath {
-			config.RecentFiles = append(config.RecentFiles[:i], config.RecentFiles[i+1:]...)
-			break
+	sorted := make([]RecentFile, len(existing))
+	copy(sorted, existing)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Pinned != sorted[j].Pinned {
+			return sorted[i].Pinned
+		}
+		return sorted[i].LastOpened.After(sorted[j].LastOpened)
+	})
+
+	return sorted, nil
+}
+
+// existingRecentFiles returns the entries whose files still exist
+func existingRecentFiles(files []RecentFile) []RecentFile {
+	existing := make([]RecentFile, 0, len(files))
+	for _, f := range files {
+		if _, err := os.Stat(f.Path); err == nil {
+			existing = append(existing, f)
 		}
 	}
+	return existing
+}
 
-	// Add to beginning of list
-	config.RecentFiles = append([]string{filePath}, config.RecentFiles...)
+// PinRecentFile pins or unpins a recent file. Pinned files are never dropped by the cap.
+func (a *App) PinRecentFile(filePath string, pinned bool) error {
+	return updateConfig(func(config *Config) error {
+		for i, f := range config.RecentFiles {
+			if f.Path == filePath {
+				config.RecentFiles[i].Pinned = pinned
+				config.RecentFiles = capRecentFiles(config.RecentFiles, config.MaxRecentFiles)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s is not a recent file", filePath)
+	})
+}
 
-	// Keep only last 10 files
-	if len(config.RecentFiles) > 10 {
-		config.RecentFiles = config.RecentFiles[:10]
+// RemoveRecentFile removes a file from the recent files list
+func (a *App) RemoveRecentFile(filePath string) error {
+	return updateConfig(func(config *Config) error {
+		for i, f := range config.RecentFiles {
+			if f.Path == filePath {
+				config.RecentFiles = append(config.RecentFiles[:i], config.RecentFiles[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// SetMaxRecentFiles sets how many unpinned recent files are kept
+func (a *App) SetMaxRecentFiles(limit int) error {
+	if limit < 1 {
+		return fmt.Errorf("max recent files must be at least 1")
 	}
 
-	return SaveConfig(config)
+	return updateConfig(func(config *Config) error {
+		config.MaxRecentFiles = limit
+		config.RecentFiles = capRecentFiles(config.RecentFiles, limit)
+		return nil
+	})
 }
 
-// GetRecentFiles returns the list of recent files
-func (a *App) GetRecentFiles() ([]string, error) {
-	config, err := LoadConfig()
-	if err != nil {
-		return nil, err
+// renameRecentFiles points recent entries for oldPath, or anything inside it, at newPath
+func renameRecentFiles(oldPath string, newPath string) error {
+	return updateConfig(func(config *Config) error {
+		for i, f := range config.RecentFiles {
+			if isSameOrChildPath(f.Path, oldPath) {
+				config.RecentFiles[i].Path = newPath + strings.TrimPrefix(f.Path, oldPath)
+			}
+		}
+		return nil
+	})
+}
+
+// capRecentFiles keeps every pinned entry and at most limit unpinned ones, preserving order
+func capRecentFiles(files []RecentFile, limit int) []RecentFile {
+	if limit < 1 {
+		limit = DefaultConfig().MaxRecentFiles
+	}
+
+	kept := make([]RecentFile, 0, len(files))
+	unpinned := 0
+	for _, f := range files {
+		if !f.Pinned {
+			if unpinned >= limit {
+				continue
+			}
+			unpinned++
+		}
+		kept = append(kept, f)
 	}
 
-	return config.RecentFiles, nil
+	return kept
 }
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// What CopyPath does when the destination already exists
+const (
+	copyConflictFail      = "fail"      // return an error (the default)
+	copyConflictSuffix    = "suffix"    // copy to "name-1", "name-2" and so on
+	copyConflictOverwrite = "overwrite" // replace the file or folder; nothing of a replaced folder is kept
+)
+
+// CopyPath copies a file or a whole folder into destDir, the source's own
+// folder when empty, and returns the path of the copy. newName renames the
+// copy and is cleaned up as in CreateFile; "" keeps the source name. conflict
+// is "fail", "suffix" or "overwrite". Relative image paths in copied notes are
+// rewritten so they still point at the same images from the new location.
+func (a *App) CopyPath(src string, destDir string, newName string, conflict string) (string, error) {
+	if src == "" {
+		return "", fmt.Errorf("source path cannot be empty")
+	}
+	switch conflict {
+	case "":
+		conflict = copyConflictFail
+	case copyConflictFail, copyConflictSuffix, copyConflictOverwrite:
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", conflict)
+	}
+
+	src = filepath.Clean(src)
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", src, err)
+	}
+	if destDir == "" {
+		destDir = filepath.Dir(src)
+	}
+
+	name := filepath.Base(src)
+	if newName != "" {
+		name, err = safeRelativeName(newName, fileNameSettings(), !info.IsDir(), filepath.Ext(src))
+		if err != nil {
+			return "", err
+		}
+	}
+	dest := filepath.Join(destDir, name)
+
+	if existing, err := os.Stat(dest); err == nil {
+		switch {
+		case conflict == copyConflictFail:
+			return "", fmt.Errorf("a file or directory named %s already exists in %s", name, destDir)
+		case conflict == copyConflictSuffix:
+			dest = uniqueCopyPath(dest, info.IsDir())
+		case os.SameFile(info, existing):
+			return "", fmt.Errorf("cannot copy %s onto itself", src)
+		case existing.IsDir() && !info.IsDir():
+			return "", fmt.Errorf("cannot overwrite the folder %s with a file", dest)
+		case !existing.IsDir() && info.IsDir():
+			return "", fmt.Errorf("cannot overwrite the file %s with a folder", dest)
+		}
+	}
+	if info.IsDir() && isSameOrChildPath(dest, src) {
+		return "", fmt.Errorf("cannot copy %s into itself", src)
+	}
+
+	if !info.IsDir() {
+		if err := copyNoteOrFile(src, dest, ""); err != nil {
+			return "", err
+		}
+		return dest, nil
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		return dest, replaceFolder(src, dest)
+	}
+	if err := copyFolder(src, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// replaceFolder replaces the folder dest with a copy of src. The copy is made
+// next to dest first, at the same depth so rewritten image paths stay right,
+// and only swapped in once complete, so a failed copy leaves dest as it was.
+func replaceFolder(src string, dest string) error {
+	if isSameOrChildPath(src, dest) {
+		return fmt.Errorf("cannot overwrite %s with %s, which is inside it", dest, src)
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(dest), "."+filepath.Base(dest)+"-copy-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary folder next to %s: %w", dest, err)
+	}
+	if err := copyFolder(src, staging); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	replaced := staging + "-replaced"
+	if err := os.Rename(dest, replaced); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move %s aside: %w", dest, err)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		if restoreErr := os.Rename(replaced, dest); restoreErr != nil {
+			return fmt.Errorf("failed to replace %s, the old folder is left at %s: %w", dest, replaced, err)
+		}
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to replace %s: %w", dest, err)
+	}
+	if err := os.RemoveAll(replaced); err != nil {
+		fmt.Printf("Warning: Could not remove the replaced folder %s: %v\n", replaced, err)
+	}
+	return nil
+}
+
+// copyFolder copies the folder src to dest, which may already exist, rewriting
+// the image paths of notes that point outside src
+func copyFolder(src string, dest string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Links are left behind rather than copied as the files they point to
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			return nil
+		}
+		return copyNoteOrFile(path, target, src)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return nil
+}
+
+// uniqueCopyPath returns path with "-1", "-2" and so on added before the
+// extension of files, choosing the first that doesn't exist
+func uniqueCopyPath(path string, isDir bool) string {
+	ext := ""
+	if !isDir {
+		ext = filepath.Ext(path)
+	}
+	stem := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", stem, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// copyNoteOrFile copies src to dest. Notes landing in another folder get their
+// relative image paths rewritten, except for images inside tree, the folder
+// being copied along with them.
+func copyNoteOrFile(src string, dest string, tree string) error {
+	oldDir, newDir := filepath.Dir(src), filepath.Dir(dest)
+	if !isMarkdownFile(src) || oldDir == newDir {
+		return copyFile(src, dest)
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to get file info for %s: %w", src, err)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", src, err)
+	}
+
+	content := rewriteImagePaths(string(data), oldDir, newDir, tree)
+	if err := os.MkdirAll(newDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+	if err := os.WriteFile(dest, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+var (
+	// imageReferencePattern matches reference-style images: ![alt][id],
+	// ![alt][] and ![alt], the last two using the alt text as the id
+	imageReferencePattern = regexp.MustCompile(`!\[([^\]]*)\](?:\[([^\]]*)\])?`)
+
+	// referenceDefinitionPattern matches a link reference definition line,
+	// capturing the label, the destination and any title after it
+	referenceDefinitionPattern = regexp.MustCompile(`^(\s{0,3}\[([^\]]+)\]:\s*)(<[^>]*>|\S+)(.*)$`)
+
+	// imageTagPattern matches the src attribute of an HTML image
+	imageTagPattern = regexp.MustCompile(`(<img\b[^>]*?\bsrc\s*=\s*)("[^"]*"|'[^']*')`)
+)
+
+// rewriteImagePaths points the relative image references of a note moving
+// from oldDir to newDir at the same files. Inline images, the definitions of
+// reference-style images and HTML <img> tags are all rewritten. References to
+// remote or missing files, and to files inside tree when tree isn't "", are
+// left alone, as are images in code blocks and front matter.
+func rewriteImagePaths(content string, oldDir string, newDir string, tree string) string {
+	// relocate returns ref relative to newDir, or false to leave it alone
+	relocate := func(ref string) (string, bool) {
+		if ref == "" || isRemoteURL(ref) || strings.HasPrefix(ref, "#") {
+			return "", false
+		}
+		decoded, err := url.PathUnescape(ref)
+		if err != nil || filepath.IsAbs(decoded) {
+			return "", false
+		}
+
+		target := filepath.Join(oldDir, filepath.FromSlash(decoded))
+		if tree != "" && isSameOrChildPath(target, tree) {
+			return "", false
+		}
+		if _, err := os.Stat(target); err != nil {
+			return "", false
+		}
+		rel, err := filepath.Rel(newDir, target)
+		if err != nil {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+	// relocateDestination relocates a markdown link destination, keeping its angle brackets
+	relocateDestination := func(ref string) (string, bool) {
+		if strings.HasPrefix(ref, "<") {
+			rel, ok := relocate(strings.TrimSuffix(strings.TrimPrefix(ref, "<"), ">"))
+			return "<" + rel + ">", ok
+		}
+		rel, ok := relocate(ref)
+		return escapeSlashPath(rel), ok
+	}
+
+	lines := strings.Split(content, "\n")
+	body := markdownBodyLines(content)
+
+	// Labels used by reference-style images, matched case-insensitively
+	imageLabels := map[string]bool{}
+	for _, line := range body {
+		if line.frontMatter || line.code {
+			continue
+		}
+		for _, parts := range imageReferencePattern.FindAllStringSubmatch(line.text, -1) {
+			label := parts[2]
+			if label == "" {
+				label = parts[1]
+			}
+			imageLabels[strings.ToLower(strings.TrimSpace(label))] = true
+		}
+	}
+
+	for i, line := range body {
+		if line.frontMatter || line.code {
+			continue
+		}
+
+		if strings.Contains(line.text, "![") {
+			lines[i] = markdownLinkPattern.ReplaceAllStringFunc(lines[i], func(match string) string {
+				parts := markdownLinkPattern.FindStringSubmatch(match)
+				if !strings.HasPrefix(parts[1], "!") {
+					return match
+				}
+				newRef, ok := relocateDestination(parts[2])
+				if !ok {
+					return match
+				}
+				return parts[1] + newRef + parts[3]
+			})
+		}
+
+		if parts := referenceDefinitionPattern.FindStringSubmatch(lines[i]); parts != nil {
+			if imageLabels[strings.ToLower(strings.TrimSpace(parts[2]))] {
+				if newRef, ok := relocateDestination(parts[3]); ok {
+					lines[i] = parts[1] + newRef + parts[4]
+				}
+			}
+		}
+
+		if strings.Contains(strings.ToLower(line.text), "<img") {
+			lines[i] = imageTagPattern.ReplaceAllStringFunc(lines[i], func(match string) string {
+				parts := imageTagPattern.FindStringSubmatch(match)
+				quote := parts[2][:1]
+				rel, ok := relocate(html.UnescapeString(parts[2][1 : len(parts[2])-1]))
+				if !ok {
+					return match
+				}
+				return parts[1] + quote + html.EscapeString(escapeSlashPath(rel)) + quote
+			})
+		}
+	}
+	return strings.Join(lines, "\n")
+}
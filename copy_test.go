@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyPathOverwriteReplacesFolder(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "src", "note.md"), "new\n")
+	writeTestFile(t, filepath.Join(root, "dest", "src", "note.md"), "old\n")
+	writeTestFile(t, filepath.Join(root, "dest", "src", "stale.md"), "stale\n")
+
+	app := NewApp()
+	dest, err := app.CopyPath(filepath.Join(root, "src"), filepath.Join(root, "dest"), "", copyConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "note.md")); string(data) != "new\n" {
+		t.Errorf("note.md reads %q, want the copied version", data)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale.md")); !os.IsNotExist(err) {
+		t.Error("a file only in the replaced folder was kept")
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "dest"))
+	if len(entries) != 1 {
+		t.Errorf("dest holds %d entries, want only the copy and no temporary folders", len(entries))
+	}
+}
+
+func TestRewriteImagePaths(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "images", "a b.png"), "png")
+	writeTestFile(t, filepath.Join(root, "images", "c.png"), "png")
+	oldDir := filepath.Join(root, "notes")
+	newDir := filepath.Join(root, "archive", "notes")
+
+	content := "![inline](../images/c.png)\n" +
+		"![ref][pic] and ![Shortcut]\n" +
+		"<img alt=\"x\" src=\"../images/a%20b.png\" width=\"10\">\n" +
+		"\n" +
+		"[pic]: <../images/a b.png> \"Title\"\n" +
+		"[shortcut]: ../images/c.png\n" +
+		"[link]: ../images/c.png\n" +
+		"```\n![code](../images/c.png)\n```\n"
+	want := "![inline](../../images/c.png)\n" +
+		"![ref][pic] and ![Shortcut]\n" +
+		"<img alt=\"x\" src=\"../../images/a%20b.png\" width=\"10\">\n" +
+		"\n" +
+		"[pic]: <../../images/a b.png> \"Title\"\n" +
+		"[shortcut]: ../../images/c.png\n" +
+		"[link]: ../images/c.png\n" +
+		"```\n![code](../images/c.png)\n```\n"
+
+	if got := rewriteImagePaths(content, oldDir, newDir, ""); got != want {
+		t.Errorf("rewriteImagePaths =\n%s\nwant\n%s", got, want)
+	}
+}
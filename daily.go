@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dailyNoteDateLayout is how dates are passed to and from the daily note methods
+const dailyNoteDateLayout = "2006-01-02"
+
+// defaultDailyNoteTemplate is used when no template file is configured
+const defaultDailyNoteTemplate = "# {{date:Monday, January 2, 2006}}\n\n"
+
+// DailyNoteSettings controls where daily notes live and how they start
+type DailyNoteSettings struct {
+	// Pattern is a Go time layout for the note path relative to the workspace,
+	// such as "journal/2006/01/2006-01-02.md"
+	Pattern string `json:"pattern"`
+	// Template is a workspace-relative file new daily notes are created from;
+	// "" uses a heading with the date
+	Template string `json:"template"`
+}
+
+// DailyNote is an existing daily note
+type DailyNote struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Path string `json:"path"`
+}
+
+// defaultDailyNoteSettings returns the settings used until the user changes them
+func defaultDailyNoteSettings() DailyNoteSettings {
+	return DailyNoteSettings{Pattern: "journal/2006/01/2006-01-02.md"}
+}
+
+// GetDailyNoteSettings returns the daily note settings
+func (a *App) GetDailyNoteSettings() (DailyNoteSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return DailyNoteSettings{}, err
+	}
+	return config.DailyNotes, nil
+}
+
+// SetDailyNoteSettings saves the daily note settings
+func (a *App) SetDailyNoteSettings(settings DailyNoteSettings) error {
+	if err := validateDailyNotePattern(settings.Pattern); err != nil {
+		return err
+	}
+	if settings.Template != "" {
+		if _, err := safeArchivePath(settings.Template); err != nil {
+			return fmt.Errorf("invalid template path: %w", err)
+		}
+	}
+
+	return updateConfig(func(config *Config) error {
+		config.DailyNotes = settings
+		return nil
+	})
+}
+
+// validateDailyNotePattern checks that a pattern gives a markdown file inside
+// the workspace and a different one for each day
+func validateDailyNotePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("daily note pattern cannot be empty")
+	}
+	if !isMarkdownFile(pattern) {
+		return fmt.Errorf("daily note pattern %q must end in .md or .markdown", pattern)
+	}
+
+	day := time.Date(2001, 2, 3, 0, 0, 0, 0, time.Local)
+	first, err := safeArchivePath(day.Format(pattern))
+	if err != nil {
+		return fmt.Errorf("invalid daily note pattern %q: %w", pattern, err)
+	}
+	if first == day.AddDate(0, 0, 1).Format(pattern) {
+		return fmt.Errorf("daily note pattern %q does not include the day", pattern)
+	}
+	return nil
+}
+
+// dailyNoteSettings loads the settings, falling back to the defaults
+func dailyNoteSettings() DailyNoteSettings {
+	config, err := LoadConfig()
+	if err != nil || config.DailyNotes.Pattern == "" {
+		return defaultDailyNoteSettings()
+	}
+	return config.DailyNotes
+}
+
+// parseDailyNoteDate parses a YYYY-MM-DD date, "" meaning today
+func parseDailyNoteDate(date string) (time.Time, error) {
+	if date == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+	day, err := time.ParseInLocation(dailyNoteDateLayout, date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
+	return day, nil
+}
+
+// OpenDailyNote opens the daily note for a YYYY-MM-DD date, today when date is
+// "", creating it from the daily note template first if it doesn't exist
+func (a *App) OpenDailyNote(date string) (CurrentFilesState, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return CurrentFilesState{}, fmt.Errorf("no workspace is open")
+	}
+	day, err := parseDailyNoteDate(date)
+	if err != nil {
+		return CurrentFilesState{}, err
+	}
+
+	settings := dailyNoteSettings()
+	if err := validateDailyNotePattern(settings.Pattern); err != nil {
+		return CurrentFilesState{}, err
+	}
+	rel, err := safeArchivePath(day.Format(settings.Pattern))
+	if err != nil {
+		return CurrentFilesState{}, fmt.Errorf("invalid daily note pattern %q: %w", settings.Pattern, err)
+	}
+	notePath := filepath.Join(root, filepath.FromSlash(rel))
+
+	if _, err := os.Stat(notePath); os.IsNotExist(err) {
+		content, err := dailyNoteContent(root, settings.Template, day, notePath)
+		if err != nil {
+			return CurrentFilesState{}, err
+		}
+		if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+			return CurrentFilesState{}, fmt.Errorf("failed to create directory for %s: %w", notePath, err)
+		}
+		if _, err := createFileWithContent(filepath.Dir(notePath), filepath.Base(notePath), content); err != nil {
+			return CurrentFilesState{}, err
+		}
+	}
+
+	return a.OpenFile(notePath)
+}
+
+// dailyNoteContent renders the daily note template for day
+func dailyNoteContent(root string, templatePath string, day time.Time, notePath string) (string, error) {
+	template := defaultDailyNoteTemplate
+	if templatePath != "" {
+		rel, err := safeArchivePath(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("invalid template path: %w", err)
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", fmt.Errorf("failed to read daily note template: %w", err)
+		}
+		template = string(data)
+	}
+
+	content, _ := renderNoteTemplate(template, noteTemplateContext{
+		Date:  day,
+		Now:   time.Now(),
+		Title: strings.TrimSuffix(filepath.Base(notePath), filepath.Ext(notePath)),
+	})
+	return content, nil
+}
+
+// PreviousDailyNote returns the latest existing daily note before a YYYY-MM-DD
+// date, or an empty DailyNote when there is none
+func (a *App) PreviousDailyNote(date string) (DailyNote, error) {
+	notes, day, err := a.dailyNotesAround(date)
+	if err != nil {
+		return DailyNote{}, err
+	}
+	for i := len(notes) - 1; i >= 0; i-- {
+		if notes[i].Date < day {
+			return notes[i], nil
+		}
+	}
+	return DailyNote{}, nil
+}
+
+// NextDailyNote returns the earliest existing daily note after a YYYY-MM-DD
+// date, or an empty DailyNote when there is none
+func (a *App) NextDailyNote(date string) (DailyNote, error) {
+	notes, day, err := a.dailyNotesAround(date)
+	if err != nil {
+		return DailyNote{}, err
+	}
+	for _, note := range notes {
+		if note.Date > day {
+			return note, nil
+		}
+	}
+	return DailyNote{}, nil
+}
+
+// dailyNotesAround returns the existing daily notes in date order and the
+// normalised form of date
+func (a *App) dailyNotesAround(date string) ([]DailyNote, string, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return nil, "", fmt.Errorf("no workspace is open")
+	}
+	day, err := parseDailyNoteDate(date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	notes, err := listDailyNotes(root, dailyNoteSettings().Pattern)
+	if err != nil {
+		return nil, "", err
+	}
+	return notes, day.Format(dailyNoteDateLayout), nil
+}
+
+// listDailyNotes finds the notes under root whose path matches pattern, oldest first
+func listDailyNotes(root string, pattern string) ([]DailyNote, error) {
+	if err := validateDailyNotePattern(pattern); err != nil {
+		return nil, err
+	}
+	pattern = path.Clean(pattern)
+
+	notes := []DailyNote{}
+	err := walkNotes(root, nil, func(p string, rel string, d fs.DirEntry) error {
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		day, err := time.ParseInLocation(pattern, rel, time.Local)
+		// Layouts can parse looser than they format, so insist on a round trip
+		if err != nil || day.Format(pattern) != rel {
+			return nil
+		}
+		notes = append(notes, DailyNote{Date: day.Format(dailyNoteDateLayout), Path: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", root, err)
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Date < notes[j].Date
+	})
+	return notes, nil
+}
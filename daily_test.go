@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListDailyNotes(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		files   []string
+		want    []string // dates, oldest first
+	}{
+		{
+			pattern: "journal/2006/01/2006-01-02.md",
+			files: []string{
+				"journal/2024/03/2024-03-05.md",
+				"journal/2023/12/2023-12-31.md",
+				"journal/2024/3/2024-03-06.md",       // month folder not zero padded
+				"journal/2024/03/2024-03-07 copy.md", // extra text
+				"journal/2024/04/2024-03-08.md",      // folder and name disagree
+				"journal/2024/03/2024-03-09.txt",
+				"2024-03-10.md",
+			},
+			want: []string{"2023-12-31", "2024-03-05"},
+		},
+		{
+			pattern: "Jan 2, 2006.md",
+			files:   []string{"Mar 5, 2024.md", "Mar 05, 2024.md", "Feb 29, 2023.md", "Feb 29, 2024.md"},
+			want:    []string{"2024-02-29", "2024-03-05"},
+		},
+	} {
+		root := t.TempDir()
+		for _, file := range tc.files {
+			writeTestFile(t, filepath.Join(root, filepath.FromSlash(file)), "# note\n")
+		}
+
+		notes, err := listDailyNotes(root, tc.pattern)
+		if err != nil {
+			t.Fatalf("listDailyNotes(%q) failed: %v", tc.pattern, err)
+		}
+		dates := []string{}
+		for _, note := range notes {
+			dates = append(dates, note.Date)
+			rel, _ := filepath.Rel(root, note.Path)
+			if day, _ := parseDailyNoteDate(note.Date); day.Format(tc.pattern) != filepath.ToSlash(rel) {
+				t.Errorf("%s is listed for %s", rel, note.Date)
+			}
+		}
+		if !reflect.DeepEqual(dates, tc.want) {
+			t.Errorf("listDailyNotes(%q) dates = %q, want %q", tc.pattern, dates, tc.want)
+		}
+	}
+
+	for _, pattern := range []string{"", "journal/2006-01.md", "2006-01-02.txt", "../2006-01-02.md"} {
+		if _, err := listDailyNotes(t.TempDir(), pattern); err == nil {
+			t.Errorf("listDailyNotes(%q) succeeded, want an invalid pattern error", pattern)
+		}
+	}
+}
@@ -0,0 +1,813 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+)
+
+const (
+	docxMaxImageWidth = 5486400 // 6 inches in EMU
+	docxEMUPerPixel   = 9525
+	docxIndentStep    = 720 // twips per list or quote level
+)
+
+// DOCXExportOptions controls how a note or folder is exported to Word
+type DOCXExportOptions struct {
+	OutputPath    string `json:"outputPath"`    // defaults to the note or folder path with a .docx extension
+	Title         string `json:"title"`         // document property, defaults to the note title or folder name
+	ReferenceDocx string `json:"referenceDocx"` // .docx whose styles replace the built-in ones
+}
+
+// docxRelationship is an entry in word/_rels/document.xml.rels
+type docxRelationship struct {
+	ID       string
+	Type     string
+	Target   string
+	External bool
+}
+
+// docxMedia is an image stored under word/media
+type docxMedia struct {
+	Name string
+	Data []byte
+}
+
+// docxImage is a packaged image and its size in EMU
+type docxImage struct {
+	RelID  string
+	Width  int
+	Height int
+}
+
+// docxNumbering is a list instance in word/numbering.xml
+type docxNumbering struct {
+	ID       int
+	Abstract int // 0 for bullets, 1 for numbers
+	Level    int
+	Start    int
+}
+
+// docxWriter turns goldmark documents into WordprocessingML
+type docxWriter struct {
+	doc       *markdownDocument
+	body      strings.Builder
+	rels      []docxRelationship
+	media     []docxMedia
+	numbering []docxNumbering
+	images    map[string]docxImage
+	usedNames map[string]bool
+	bookmarks int
+	drawings  int
+	noteIndex int // of the note being written, counting from 1, to keep bookmarks apart
+
+	// bookmarkNames maps a note index and anchor to its bookmark, and
+	// usedBookmarks holds the names taken, so truncated names stay unique
+	bookmarkNames map[string]string
+	usedBookmarks map[string]bool
+
+	// Run formatting
+	bold      bool
+	italic    bool
+	strike    bool
+	code      bool
+	link      bool
+	superText bool
+
+	// Paragraph context
+	listDepth int
+	quote     int
+}
+
+const (
+	docxRelStyles    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+	docxRelNumbering = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	docxRelHyperlink = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	docxRelImage     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+)
+
+// docxImageTypes are the image formats Word displays, by extension
+var docxImageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+}
+
+var docxContentTypesTemplate = template.Must(template.New("content-types").Parse(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+{{- range $ext, $type := .}}
+<Default Extension="{{$ext}}" ContentType="{{$type}}"/>
+{{- end}}
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+</Types>
+`))
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>
+`
+
+var docxDocumentRelsTemplate = template.Must(template.New("document-rels").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+{{- range .}}
+<Relationship Id="{{.ID}}" Type="{{.Type}}" Target="{{xml .Target}}"{{if .External}} TargetMode="External"{{end}}/>
+{{- end}}
+</Relationships>
+`))
+
+var docxCoreTemplate = template.Must(template.New("core").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<dc:title>{{xml .Title}}</dc:title>
+<dcterms:created xsi:type="dcterms:W3CDTF">{{.Created}}</dcterms:created>
+<dcterms:modified xsi:type="dcterms:W3CDTF">{{.Created}}</dcterms:modified>
+</cp:coreProperties>
+`))
+
+var docxDocumentTemplate = template.Must(template.New("document").Parse(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">
+<w:body>
+{{.}}
+<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr>
+</w:body>
+</w:document>
+`))
+
+var docxNumberingTemplate = template.Must(template.New("numbering").Funcs(template.FuncMap{
+	"levels": func() []int { return []int{0, 1, 2, 3, 4, 5, 6, 7, 8} },
+	"inc":    func(i int) int { return i + 1 },
+	"indent": func(level int) int { return docxIndentStep * (level + 1) },
+	"bullet": func(level int) string { return []string{"•", "◦", "▪"}[level%3] },
+}).Parse(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0">
+<w:multiLevelType w:val="hybridMultilevel"/>
+{{- range levels}}
+<w:lvl w:ilvl="{{.}}"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="{{bullet .}}"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="{{indent .}}" w:hanging="360"/></w:pPr></w:lvl>
+{{- end}}
+</w:abstractNum>
+<w:abstractNum w:abstractNumId="1">
+<w:multiLevelType w:val="hybridMultilevel"/>
+{{- range levels}}
+<w:lvl w:ilvl="{{.}}"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%{{inc .}}."/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="{{indent .}}" w:hanging="360"/></w:pPr></w:lvl>
+{{- end}}
+</w:abstractNum>
+{{- range .}}
+<w:num w:numId="{{.ID}}"><w:abstractNumId w:val="{{.Abstract}}"/><w:lvlOverride w:ilvl="{{.Level}}"><w:startOverride w:val="{{.Start}}"/></w:lvlOverride></w:num>
+{{- end}}
+</w:numbering>
+`))
+
+// docxDefaultStyles are used unless a reference document supplies its own.
+// Reference documents should define the same style IDs to style every element.
+const docxDefaultStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="en-US"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:sz w:val="52"/><w:szCs w:val="52"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/><w:szCs w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/><w:szCs w:val="28"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="26"/><w:szCs w:val="26"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading4"><w:name w:val="heading 4"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="80"/><w:outlineLvl w:val="3"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading5"><w:name w:val="heading 5"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="80"/><w:outlineLvl w:val="4"/></w:pPr><w:rPr><w:b/><w:i/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading6"><w:name w:val="heading 6"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="80"/><w:outlineLvl w:val="5"/></w:pPr><w:rPr><w:i/><w:color w:val="595959"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr><w:pBdr><w:left w:val="single" w:sz="18" w:space="8" w:color="D0D7DE"/></w:pBdr></w:pPr><w:rPr><w:i/><w:color w:val="595959"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="SourceCode"><w:name w:val="Source Code"/><w:basedOn w:val="Normal"/><w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F6F8FA"/><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:after="60"/><w:contextualSpacing/></w:pPr></w:style>
+<w:style w:type="character" w:default="1" w:styleId="DefaultParagraphFont"><w:name w:val="Default Paragraph Font"/><w:uiPriority w:val="1"/><w:semiHidden/></w:style>
+<w:style w:type="character" w:styleId="VerbatimChar"><w:name w:val="Verbatim Char"/><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="20"/><w:shd w:val="clear" w:color="auto" w:fill="F6F8FA"/></w:rPr></w:style>
+<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:rPr><w:color w:val="0563C1"/><w:u w:val="single"/></w:rPr></w:style>
+<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/><w:semiHidden/><w:tblPr><w:tblInd w:w="0" w:type="dxa"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>
+<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:basedOn w:val="TableNormal"/><w:pPr><w:spacing w:after="0"/></w:pPr><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:left w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:bottom w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:right w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:insideH w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/></w:tblBorders></w:tblPr></w:style>
+</w:styles>
+`
+
+// ExportDOCX converts a note, or every note in a folder, to a Word document and returns its path
+func (a *App) ExportDOCX(path string, options DOCXExportOptions) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+
+	outputPath := options.OutputPath
+	if outputPath == "" {
+		if info.IsDir() {
+			outputPath = filepath.Clean(path) + ".docx"
+		} else {
+			outputPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".docx"
+		}
+	}
+
+	var files []string
+	if info.IsDir() {
+		files, err = collectMarkdownFiles(path, noteSkipDirs(a.workspaceRoot()))
+		if err != nil {
+			return "", err
+		}
+		if len(files) == 0 {
+			return "", fmt.Errorf("no markdown files found in %s", path)
+		}
+	} else {
+		files = []string{path}
+	}
+
+	styles := docxDefaultStyles
+	if options.ReferenceDocx != "" {
+		styles, err = readReferenceStyles(options.ReferenceDocx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w := &docxWriter{
+		images:        map[string]docxImage{},
+		usedNames:     map[string]bool{},
+		bookmarkNames: map[string]string{},
+		usedBookmarks: map[string]bool{},
+		rels: []docxRelationship{
+			{ID: "rId1", Type: docxRelStyles, Target: "styles.xml"},
+			{ID: "rId2", Type: docxRelNumbering, Target: "numbering.xml"},
+		},
+	}
+
+	md := newMarkdown("")
+	for i, file := range files {
+		doc, err := parseMarkdownFile(md, file)
+		if err != nil {
+			return "", err
+		}
+		w.noteIndex = i + 1
+		if !info.IsDir() && options.Title == "" {
+			options.Title = doc.title()
+		}
+		if i > 0 {
+			w.body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		}
+		// Folder exports open each note with its title, unless it already starts with one
+		if info.IsDir() && !startsWithTitle(doc) {
+			w.writeParagraph("Heading1", func() { w.writeText(doc.title()) })
+		}
+		w.doc = doc
+		w.writeBlocks(doc.Root)
+	}
+	if options.Title == "" {
+		options.Title = filepath.Base(filepath.Clean(path))
+	}
+
+	if err := w.save(outputPath, styles, options.Title); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// readReferenceStyles returns the styles part of a reference document
+func readReferenceStyles(path string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open reference document %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "word/styles.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to read styles from %s: %w", path, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return "", fmt.Errorf("failed to read styles from %s: %w", path, err)
+		}
+		return string(data), nil
+	}
+
+	return "", fmt.Errorf("reference document %s has no styles", path)
+}
+
+// save packages the document into outputPath
+func (w *docxWriter) save(outputPath string, styles string, title string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputPath, err)
+	}
+	defer file.Close()
+
+	contentTypes := map[string]string{}
+	for _, m := range w.media {
+		ext := strings.ToLower(filepath.Ext(m.Name))
+		contentTypes[strings.TrimPrefix(ext, ".")] = docxImageTypes[ext]
+	}
+
+	core := struct {
+		Title   string
+		Created string
+	}{title, time.Now().UTC().Format(time.RFC3339)}
+
+	parts := []struct {
+		name   string
+		render func(io.Writer) error
+	}{
+		{"[Content_Types].xml", func(out io.Writer) error { return docxContentTypesTemplate.Execute(out, contentTypes) }},
+		{"_rels/.rels", func(out io.Writer) error {
+			_, err := io.WriteString(out, docxPackageRels)
+			return err
+		}},
+		{"docProps/core.xml", func(out io.Writer) error { return docxCoreTemplate.Execute(out, core) }},
+		{"word/document.xml", func(out io.Writer) error { return docxDocumentTemplate.Execute(out, w.body.String()) }},
+		{"word/_rels/document.xml.rels", func(out io.Writer) error { return docxDocumentRelsTemplate.Execute(out, w.rels) }},
+		{"word/styles.xml", func(out io.Writer) error {
+			_, err := io.WriteString(out, styles)
+			return err
+		}},
+		{"word/numbering.xml", func(out io.Writer) error { return docxNumberingTemplate.Execute(out, w.numbering) }},
+	}
+
+	zw := zip.NewWriter(file)
+	for _, part := range parts {
+		out, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+		if err := part.render(out); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+	for _, m := range w.media {
+		out, err := zw.Create("word/media/" + m.Name)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+		if _, err := out.Write(m.Data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", m.Name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return file.Close()
+}
+
+// addRelationship registers a document relationship and returns its ID
+func (w *docxWriter) addRelationship(relType string, target string, external bool) string {
+	id := fmt.Sprintf("rId%d", len(w.rels)+1)
+	w.rels = append(w.rels, docxRelationship{ID: id, Type: relType, Target: target, External: external})
+	return id
+}
+
+// writeBlocks writes the block children of n
+func (w *docxWriter) writeBlocks(n ast.Node) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		w.writeBlock(child)
+	}
+}
+
+// writeBlock writes a single block node
+func (w *docxWriter) writeBlock(n ast.Node) {
+	switch node := n.(type) {
+	case *ast.Heading:
+		style := fmt.Sprintf("Heading%d", node.Level)
+		w.writeParagraph(style, func() {
+			if id, ok := node.AttributeString("id"); ok {
+				if anchor, ok := id.([]byte); ok {
+					w.writeBookmark(string(anchor))
+				}
+			}
+			w.writeInlines(node)
+		})
+
+	case *ast.Paragraph, *ast.TextBlock:
+		w.writeParagraph(w.paragraphStyle(), func() { w.writeInlines(node) })
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		w.writeCode(node)
+
+	case *ast.Blockquote:
+		w.quote++
+		w.writeBlocks(node)
+		w.quote--
+
+	case *ast.List:
+		w.writeList(node)
+
+	case *ast.ThematicBreak:
+		w.body.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="D0D7DE"/></w:pBdr></w:pPr></w:p>`)
+
+	case *extast.Table:
+		w.writeTable(node)
+
+	case *extast.FootnoteList:
+		w.writeParagraph("Heading2", func() { w.writeText("Notes") })
+		index := 1
+		for footnote := node.FirstChild(); footnote != nil; footnote = footnote.NextSibling() {
+			for child := footnote.FirstChild(); child != nil; child = child.NextSibling() {
+				prefix := ""
+				if child == footnote.FirstChild() {
+					prefix = fmt.Sprintf("%d. ", index)
+				}
+				w.writeParagraph(w.paragraphStyle(), func() {
+					w.writeText(prefix)
+					w.writeInlines(child)
+				})
+			}
+			index++
+		}
+
+	case *ast.HTMLBlock:
+		// Raw HTML has no Word equivalent; skip it
+
+	default:
+		w.writeBlocks(node)
+	}
+}
+
+// paragraphStyle returns the style for body text in the current context
+func (w *docxWriter) paragraphStyle() string {
+	if w.quote > 0 {
+		return "Quote"
+	}
+	return ""
+}
+
+// writeParagraph writes a paragraph with the given style, indented for the
+// current list and quote depth, whose runs are written by content
+func (w *docxWriter) writeParagraph(style string, content func()) {
+	w.writeParagraphWithNumbering(style, 0, content)
+}
+
+// writeParagraphWithNumbering writes a paragraph that is a list item when numID is set
+func (w *docxWriter) writeParagraphWithNumbering(style string, numID int, content func()) {
+	w.body.WriteString("<w:p><w:pPr>")
+	if style != "" {
+		fmt.Fprintf(&w.body, `<w:pStyle w:val="%s"/>`, style)
+	}
+	if numID > 0 {
+		fmt.Fprintf(&w.body, `<w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr>`, w.listDepth-1, numID)
+	} else if indent := docxIndentStep * (w.listDepth + w.quote); indent > 0 {
+		fmt.Fprintf(&w.body, `<w:ind w:left="%d"/>`, indent)
+	}
+	w.body.WriteString("</w:pPr>")
+	content()
+	w.body.WriteString("</w:p>")
+}
+
+// writeBookmark marks a heading so "#anchor" links can jump to it
+func (w *docxWriter) writeBookmark(anchor string) {
+	name := w.bookmarkName(anchor)
+	w.bookmarks++
+	fmt.Fprintf(&w.body, `<w:bookmarkStart w:id="%d" w:name="%s"/><w:bookmarkEnd w:id="%d"/>`, w.bookmarks, xmlEscape(name), w.bookmarks)
+}
+
+// bookmarkName returns the bookmark for a heading anchor in the current note,
+// the same one for the heading and every link to it. The note's index keeps
+// headings with the same text in different notes of a folder export apart.
+// Word limits the name to 40 characters, so a longer one is cut short and
+// numbered to keep it from colliding with another that starts the same way.
+func (w *docxWriter) bookmarkName(anchor string) string {
+	key := fmt.Sprintf("%d#%s", w.noteIndex, anchor)
+	if name, ok := w.bookmarkNames[key]; ok {
+		return name
+	}
+
+	full := []rune(fmt.Sprintf("n%d_%s", w.noteIndex, anchor))
+	name := string(full)
+	if len(full) > 40 || w.usedBookmarks[name] {
+		for n := 1; n == 1 || w.usedBookmarks[name]; n++ {
+			suffix := fmt.Sprintf("_%d", n)
+			name = string(full[:min(len(full), 40-len(suffix))]) + suffix
+		}
+	}
+
+	w.bookmarkNames[key] = name
+	w.usedBookmarks[name] = true
+	return name
+}
+
+// writeList writes a bulleted, numbered or task list. Each list gets its own
+// numbering instance so numbered lists restart where markdown restarts them.
+func (w *docxWriter) writeList(list *ast.List) {
+	w.listDepth++
+	defer func() { w.listDepth-- }()
+
+	numbering := docxNumbering{ID: len(w.numbering) + 1, Level: w.listDepth - 1, Start: 1}
+	if list.IsOrdered() {
+		numbering.Abstract = 1
+		numbering.Start = list.Start
+	}
+	w.numbering = append(w.numbering, numbering)
+
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			switch child.(type) {
+			case *ast.Paragraph, *ast.TextBlock:
+				numID := 0
+				if child == item.FirstChild() {
+					numID = numbering.ID
+				}
+				w.writeParagraphWithNumbering("ListParagraph", numID, func() { w.writeInlines(child) })
+			default:
+				w.writeBlock(child)
+			}
+		}
+	}
+}
+
+// writeCode writes a code block as one shaded paragraph with line breaks
+func (w *docxWriter) writeCode(n ast.Node) {
+	var buf bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(w.doc.Source))
+	}
+	code := strings.TrimRight(strings.ReplaceAll(buf.String(), "\t", "    "), "\n")
+
+	w.writeParagraph("SourceCode", func() {
+		w.body.WriteString("<w:r>")
+		for i, line := range strings.Split(code, "\n") {
+			if i > 0 {
+				w.body.WriteString("<w:br/>")
+			}
+			fmt.Fprintf(&w.body, `<w:t xml:space="preserve">%s</w:t>`, xmlEscape(line))
+		}
+		w.body.WriteString("</w:r>")
+	})
+}
+
+// writeTable writes a GFM table with a repeating bold header row
+func (w *docxWriter) writeTable(table *extast.Table) {
+	columns := len(table.Alignments)
+	if columns == 0 {
+		return
+	}
+
+	w.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for i := 0; i < columns; i++ {
+		fmt.Fprintf(&w.body, `<w:gridCol w:w="%d"/>`, 9026/columns)
+	}
+	w.body.WriteString("</w:tblGrid>")
+
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		_, header := row.(*extast.TableHeader)
+		w.body.WriteString("<w:tr>")
+		if header {
+			w.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		column := 0
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			w.body.WriteString(`<w:tc><w:tcPr><w:tcW w:w="0" w:type="auto"/>`)
+			if header {
+				w.body.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/>`)
+			}
+			w.body.WriteString("</w:tcPr><w:p><w:pPr>")
+			if column < columns {
+				switch table.Alignments[column] {
+				case extast.AlignCenter:
+					w.body.WriteString(`<w:jc w:val="center"/>`)
+				case extast.AlignRight:
+					w.body.WriteString(`<w:jc w:val="right"/>`)
+				}
+			}
+			w.body.WriteString("</w:pPr>")
+			w.bold = header
+			w.writeInlines(cell)
+			w.bold = false
+			w.body.WriteString("</w:p></w:tc>")
+			column++
+		}
+		w.body.WriteString("</w:tr>")
+	}
+
+	w.body.WriteString("</w:tbl>")
+	// Word merges adjacent tables; an empty paragraph keeps them apart
+	w.body.WriteString("<w:p/>")
+}
+
+// writeInlines writes the inline children of n as runs
+func (w *docxWriter) writeInlines(n ast.Node) {
+	source := w.doc.Source
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch node := child.(type) {
+		case *ast.Text:
+			w.writeText(string(node.Segment.Value(source)))
+			if node.HardLineBreak() {
+				w.body.WriteString("<w:r><w:br/></w:r>")
+			} else if node.SoftLineBreak() {
+				w.writeText(" ")
+			}
+
+		case *ast.String:
+			w.writeText(string(node.Value))
+
+		case *ast.CodeSpan:
+			w.code = true
+			w.writeText(nodeText(node, source))
+			w.code = false
+
+		case *ast.Emphasis:
+			wasBold, wasItalic := w.bold, w.italic
+			if node.Level >= 2 {
+				w.bold = true
+			} else {
+				w.italic = true
+			}
+			w.writeInlines(node)
+			w.bold, w.italic = wasBold, wasItalic
+
+		case *ast.Link:
+			w.writeHyperlink(string(node.Destination), func() { w.writeInlines(node) })
+
+		case *ast.AutoLink:
+			label := string(node.Label(source))
+			w.writeHyperlink(string(node.URL(source)), func() { w.writeText(label) })
+
+		case *ast.Image:
+			w.writeImage(node)
+
+		case *extast.TaskCheckBox:
+			if node.IsChecked {
+				w.writeText("☒ ")
+			} else {
+				w.writeText("☐ ")
+			}
+
+		case *extast.Strikethrough:
+			w.strike = true
+			w.writeInlines(node)
+			w.strike = false
+
+		case *extast.FootnoteLink:
+			w.superText = true
+			w.writeText(fmt.Sprintf("%d", node.Index))
+			w.superText = false
+
+		case *extast.FootnoteBacklink, *ast.RawHTML:
+			// No Word equivalent; skip it
+
+		default:
+			w.writeInlines(node)
+		}
+	}
+}
+
+// writeText writes a run with the current formatting
+func (w *docxWriter) writeText(text string) {
+	if text == "" {
+		return
+	}
+
+	var props strings.Builder
+	if w.link {
+		props.WriteString(`<w:rStyle w:val="Hyperlink"/>`)
+	} else if w.code {
+		props.WriteString(`<w:rStyle w:val="VerbatimChar"/>`)
+	}
+	if w.bold {
+		props.WriteString("<w:b/>")
+	}
+	if w.italic {
+		props.WriteString("<w:i/>")
+	}
+	if w.strike {
+		props.WriteString("<w:strike/>")
+	}
+	if w.superText {
+		props.WriteString(`<w:vertAlign w:val="superscript"/>`)
+	}
+
+	w.body.WriteString("<w:r>")
+	if props.Len() > 0 {
+		fmt.Fprintf(&w.body, "<w:rPr>%s</w:rPr>", props.String())
+	}
+	fmt.Fprintf(&w.body, `<w:t xml:space="preserve">%s</w:t></w:r>`, xmlEscape(text))
+}
+
+// writeHyperlink wraps the runs written by content in a link. "#anchor"
+// links point at heading bookmarks; everything else is an external target.
+func (w *docxWriter) writeHyperlink(destination string, content func()) {
+	if destination == "" {
+		content()
+		return
+	}
+
+	if anchor, ok := strings.CutPrefix(destination, "#"); ok {
+		fmt.Fprintf(&w.body, `<w:hyperlink w:anchor="%s">`, xmlEscape(w.bookmarkName(anchor)))
+	} else {
+		id := w.addRelationship(docxRelHyperlink, destination, true)
+		fmt.Fprintf(&w.body, `<w:hyperlink r:id="%s">`, id)
+	}
+
+	w.link = true
+	content()
+	w.link = false
+	w.body.WriteString("</w:hyperlink>")
+}
+
+// writeImage embeds a local image inline, scaled to the page width. Images
+// that can't be embedded are replaced by their alt text.
+func (w *docxWriter) writeImage(img *ast.Image) {
+	alt := nodeText(img, w.doc.Source)
+
+	packaged, err := w.addImage(string(img.Destination))
+	if err != nil {
+		fmt.Printf("Warning: Could not embed image %s in %s: %v\n", img.Destination, w.doc.Path, err)
+		w.writeText("[" + alt + "]")
+		return
+	}
+
+	w.drawings++
+	id := w.drawings
+	fmt.Fprintf(&w.body, `<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%d" cy="%d"/><wp:docPr id="%d" name="Picture %d" descr="%s"/>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic><pic:nvPicPr><pic:cNvPr id="%d" name="Picture %d"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`,
+		packaged.Width, packaged.Height, id, id, xmlEscape(alt),
+		id, id, packaged.RelID, packaged.Width, packaged.Height)
+}
+
+// addImage packages a local image once and returns its relationship and size
+func (w *docxWriter) addImage(ref string) (docxImage, error) {
+	if isRemoteURL(ref) {
+		return docxImage{}, fmt.Errorf("remote images are not embedded")
+	}
+
+	resolved, err := resolveImagePath(filepath.Dir(w.doc.Path), ref)
+	if err != nil {
+		return docxImage{}, err
+	}
+	if packaged, ok := w.images[resolved]; ok {
+		return packaged, nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(resolved))
+	if _, ok := docxImageTypes[ext]; !ok {
+		return docxImage{}, fmt.Errorf("unsupported image type %s", ext)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return docxImage{}, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return docxImage{}, fmt.Errorf("unsupported image format: %w", err)
+	}
+
+	width := config.Width * docxEMUPerPixel
+	height := config.Height * docxEMUPerPixel
+	if width > docxMaxImageWidth {
+		height = height * docxMaxImageWidth / width
+		width = docxMaxImageWidth
+	}
+
+	name := uniqueFileName(sanitizePackageName(filepath.Base(resolved)), w.usedNames)
+	w.media = append(w.media, docxMedia{Name: name, Data: data})
+
+	packaged := docxImage{
+		RelID:  w.addRelationship(docxRelImage, "media/"+name, false),
+		Width:  width,
+		Height: height,
+	}
+	w.images[resolved] = packaged
+	return packaged, nil
+}
+
+// xmlEscape escapes text for use in XML content and attribute values
+func xmlEscape(text string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
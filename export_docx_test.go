@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDocxBookmarkNamesStayUnique(t *testing.T) {
+	w := &docxWriter{bookmarkNames: map[string]string{}, usedBookmarks: map[string]bool{}, noteIndex: 1}
+	long := strings.Repeat("heading-", 6)
+	anchors := []string{"intro", long + "one", long + "two", long + "two-1", "intro"}
+
+	names := map[string]string{}
+	for _, anchor := range anchors {
+		name := w.bookmarkName(anchor)
+		if utf8.RuneCountInString(name) > 40 {
+			t.Errorf("bookmark %q for %q is longer than 40 characters", name, anchor)
+		}
+		if other, ok := names[name]; ok && other != anchor {
+			t.Errorf("%q and %q share the bookmark %q", other, anchor, name)
+		}
+		names[name] = anchor
+	}
+	if got := w.bookmarkName("intro"); got != "n1_intro" {
+		t.Errorf("bookmarkName(intro) = %q, want n1_intro", got)
+	}
+
+	w.noteIndex = 2
+	if got := w.bookmarkName("intro"); got != "n2_intro" {
+		t.Errorf("bookmarkName(intro) in the second note = %q, want n2_intro", got)
+	}
+}
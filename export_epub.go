@@ -0,0 +1,810 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// EPUBExportOptions controls how a note or folder is exported to EPUB
+type EPUBExportOptions struct {
+	OutputPath string `json:"outputPath"` // defaults to the note or folder path with an .epub extension
+	Title      string `json:"title"`      // defaults to the note title or folder name
+	Author     string `json:"author"`
+	Language   string `json:"language"` // BCP 47 tag, defaults to "en"
+}
+
+// epubChapter is one XHTML document in the book
+type epubChapter struct {
+	ID       string
+	FileName string // relative to OEBPS, e.g. "text/chapter-001.xhtml"
+	Title    string
+	Body     string
+}
+
+// epubImage is an image packaged in the book
+type epubImage struct {
+	ID        string
+	FileName  string // relative to OEBPS
+	MediaType string
+	Source    string
+}
+
+// epubMediaTypes are the image types EPUB 3 reading systems must support
+var epubMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+// epubBook collects the chapters and images of a book while it is built
+type epubBook struct {
+	md        goldmark.Markdown
+	chapters  []*epubChapter
+	images    map[string]*epubImage // by absolute source path
+	imageList []*epubImage
+	usedNames map[string]bool
+	files     map[string]string            // note path -> chapter file, for cross links
+	anchors   map[string]map[string]string // note path -> heading id -> chapter file
+}
+
+var epubChapterTemplate = template.Must(template.New("chapter").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<meta charset="UTF-8" />
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="../style.css" />
+</head>
+<body>
+<section epub:type="chapter">
+{{.Body}}
+</section>
+</body>
+</html>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<meta charset="UTF-8" />
+<title>{{.Title}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>Contents</h1>
+<ol>
+{{- range .Chapters}}
+<li><a href="{{.FileName}}">{{.Title}}</a></li>
+{{- end}}
+</ol>
+</nav>
+</body>
+</html>
+`))
+
+var epubOPFTemplate = template.Must(template.New("opf").Parse(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{.Language}}">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="book-id">urn:uuid:{{.ID}}</dc:identifier>
+<dc:title>{{.Title}}</dc:title>
+<dc:language>{{.Language}}</dc:language>
+{{- if .Author}}
+<dc:creator>{{.Author}}</dc:creator>
+{{- end}}
+<meta property="dcterms:modified">{{.Modified}}</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav" />
+<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml" />
+<item id="style" href="style.css" media-type="text/css" />
+{{- range .Chapters}}
+<item id="{{.ID}}" href="{{.FileName}}" media-type="application/xhtml+xml" />
+{{- end}}
+{{- range .Images}}
+<item id="{{.ID}}" href="{{.FileName}}" media-type="{{.MediaType}}" />
+{{- end}}
+</manifest>
+<spine toc="ncx">
+{{- range .Chapters}}
+<itemref idref="{{.ID}}" />
+{{- end}}
+</spine>
+</package>
+`))
+
+var epubNCXTemplate = template.Must(template.New("ncx").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head>
+<meta name="dtb:uid" content="urn:uuid:{{.ID}}" />
+<meta name="dtb:depth" content="1" />
+<meta name="dtb:totalPageCount" content="0" />
+<meta name="dtb:maxPageNumber" content="0" />
+</head>
+<docTitle><text>{{.Title}}</text></docTitle>
+<navMap>
+{{- range $i, $c := .Chapters}}
+<navPoint id="nav-{{$c.ID}}" playOrder="{{inc $i}}">
+<navLabel><text>{{$c.Title}}</text></navLabel>
+<content src="{{$c.FileName}}" />
+</navPoint>
+{{- end}}
+</navMap>
+</ncx>
+`))
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml" />
+</rootfiles>
+</container>
+`
+
+const epubCSS = `body { font-family: serif; line-height: 1.5; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.25; }
+pre, code { font-family: monospace; font-size: 0.9em; }
+pre { white-space: pre-wrap; padding: 0.5em; background: #f6f8fa; }
+img { max-width: 100%; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 0.25em 0.5em; }
+blockquote { margin-left: 1em; padding-left: 1em; border-left: 3px solid #ccc; }
+`
+
+// epubPackage is the data for the OPF and NCX templates
+type epubPackage struct {
+	ID       string
+	Title    string
+	Author   string
+	Language string
+	Modified string
+	Chapters []*epubChapter
+	Images   []*epubImage
+}
+
+// ExportEPUB builds an EPUB 3 book from a note, split into chapters at its
+// top-level headings, or from a folder with one chapter per note
+func (a *App) ExportEPUB(path string, options EPUBExportOptions) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+
+	outputPath := options.OutputPath
+	if outputPath == "" {
+		if info.IsDir() {
+			outputPath = filepath.Clean(path) + ".epub"
+		} else {
+			outputPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".epub"
+		}
+	}
+	if options.Language == "" {
+		options.Language = "en"
+	}
+
+	book := &epubBook{
+		md:        newXHTMLMarkdown(),
+		images:    map[string]*epubImage{},
+		usedNames: map[string]bool{},
+		files:     map[string]string{},
+		anchors:   map[string]map[string]string{},
+	}
+
+	if info.IsDir() {
+		files, err := collectMarkdownFiles(path, noteSkipDirs(a.workspaceRoot()))
+		if err != nil {
+			return "", err
+		}
+		if len(files) == 0 {
+			return "", fmt.Errorf("no markdown files found in %s", path)
+		}
+		if err := book.addFolder(files); err != nil {
+			return "", err
+		}
+		if options.Title == "" {
+			options.Title = filepath.Base(filepath.Clean(path))
+		}
+	} else {
+		title, err := book.addNote(path)
+		if err != nil {
+			return "", err
+		}
+		if options.Title == "" {
+			options.Title = title
+		}
+	}
+
+	if err := book.write(outputPath, options); err != nil {
+		return "", err
+	}
+
+	// Catch structural problems before the file reaches a reader
+	if err := validateEPUB(outputPath); err != nil {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("generated EPUB is invalid: %w", err)
+	}
+
+	return outputPath, nil
+}
+
+// newXHTMLMarkdown returns a converter that produces XHTML for EPUB chapters.
+// Raw HTML is dropped since it is rarely well-formed XML.
+func newXHTMLMarkdown() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle("github"),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+		goldmark.WithRendererOptions(
+			html.WithXHTML(),
+		),
+	)
+}
+
+// addFolder adds one chapter per note. Notes are ordered by an "order" front
+// matter value when present, then by path.
+func (b *epubBook) addFolder(files []string) error {
+	docs := make([]*markdownDocument, 0, len(files))
+	for _, file := range files {
+		doc, err := parseMarkdownFile(b.md, file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		oi, errI := strconv.Atoi(docs[i].FrontMatter["order"])
+		oj, errJ := strconv.Atoi(docs[j].FrontMatter["order"])
+		if errI == nil && errJ == nil {
+			return oi < oj
+		}
+		// Notes with an order come before those without
+		return errI == nil && errJ != nil
+	})
+
+	// Assign file names first so notes can link to each other
+	for i, doc := range docs {
+		b.files[doc.Path] = epubChapterFileName(i + 1)
+		b.addAnchors(doc.Path, doc.Root, epubChapterFileName(i+1))
+	}
+
+	for _, doc := range docs {
+		body, err := b.renderNode(doc, doc.Root)
+		if err != nil {
+			return err
+		}
+		b.addChapter(doc.title(), body)
+	}
+
+	return nil
+}
+
+// addNote splits a single note into chapters at its top-level headings and returns its title
+func (b *epubBook) addNote(path string) (string, error) {
+	doc, err := parseMarkdownFile(b.md, path)
+	if err != nil {
+		return "", err
+	}
+	b.files[doc.Path] = epubChapterFileName(len(b.chapters) + 1)
+
+	// The shallowest heading level present is the chapter level
+	chapterLevel := 0
+	for n := doc.Root.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.Heading); ok && (chapterLevel == 0 || h.Level < chapterLevel) {
+			chapterLevel = h.Level
+		}
+	}
+
+	// Move the top-level blocks into one document per chapter
+	var sections []*ast.Document
+	var titles []string
+	noteTitle := doc.title()
+	current := ast.NewDocument()
+	currentTitle := noteTitle
+	if chapterLevel > 0 && doc.FrontMatter["title"] == "" {
+		// Text before the first heading would otherwise share its title
+		currentTitle = "Preface"
+	}
+	for n := doc.Root.FirstChild(); n != nil; {
+		next := n.NextSibling()
+		if h, ok := n.(*ast.Heading); ok && h.Level == chapterLevel && current.HasChildren() {
+			sections = append(sections, current)
+			titles = append(titles, currentTitle)
+			current = ast.NewDocument()
+		}
+		if h, ok := n.(*ast.Heading); ok && h.Level == chapterLevel {
+			currentTitle = nodeText(h, doc.Source)
+		}
+		doc.Root.RemoveChild(doc.Root, n)
+		current.AppendChild(current, n)
+		n = next
+	}
+	if current.HasChildren() || len(sections) == 0 {
+		sections = append(sections, current)
+		titles = append(titles, currentTitle)
+	}
+
+	// Headings move to the chapter holding them, and links to them follow
+	for i, section := range sections {
+		b.addAnchors(doc.Path, section, epubChapterFileName(len(b.chapters)+i+1))
+	}
+
+	for i, section := range sections {
+		body, err := b.renderNode(doc, section)
+		if err != nil {
+			return "", err
+		}
+		b.addChapter(titles[i], body)
+	}
+
+	return noteTitle, nil
+}
+
+// addAnchors records that the headings under root end up in the chapter file
+func (b *epubBook) addAnchors(notePath string, root ast.Node, file string) {
+	if b.anchors[notePath] == nil {
+		b.anchors[notePath] = map[string]string{}
+	}
+	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			if id, ok := h.AttributeString("id"); ok {
+				if idBytes, ok := id.([]byte); ok {
+					b.anchors[notePath][string(idBytes)] = file
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// epubChapterFileName returns the file name of the chapter with number, counting from 1
+func epubChapterFileName(number int) string {
+	return fmt.Sprintf("text/chapter-%03d.xhtml", number)
+}
+
+// addChapter appends a chapter with the next file name
+func (b *epubBook) addChapter(title string, body string) {
+	number := len(b.chapters) + 1
+	b.chapters = append(b.chapters, &epubChapter{
+		ID:       fmt.Sprintf("chapter-%03d", number),
+		FileName: epubChapterFileName(number),
+		Title:    title,
+		Body:     body,
+	})
+}
+
+// renderNode packages the images under root, points note links at chapters and renders XHTML
+func (b *epubBook) renderNode(doc *markdownDocument, root ast.Node) (string, error) {
+	baseDir := filepath.Dir(doc.Path)
+
+	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Image:
+			dest := string(node.Destination)
+			if dest == "" || isRemoteURL(dest) {
+				return ast.WalkContinue, nil
+			}
+			resolved, err := resolveImagePath(baseDir, dest)
+			if err != nil {
+				fmt.Printf("Warning: Could not resolve image %s in %s: %v\n", dest, doc.Path, err)
+				return ast.WalkContinue, nil
+			}
+			img, err := b.addImage(resolved)
+			if err != nil {
+				fmt.Printf("Warning: Could not package image %s: %v\n", resolved, err)
+				return ast.WalkContinue, nil
+			}
+			node.Destination = []byte("../" + img.FileName)
+
+		case *ast.Link:
+			dest := string(node.Destination)
+			if dest == "" || isRemoteURL(dest) {
+				return ast.WalkContinue, nil
+			}
+			target, fragment, _ := strings.Cut(dest, "#")
+			resolved := doc.Path
+			if target != "" {
+				var err error
+				if resolved, err = resolveImagePath(baseDir, target); err != nil {
+					return ast.WalkContinue, nil
+				}
+			}
+			// Point at the chapter holding the heading, which may not be the
+			// one holding the link once a note is split
+			file, ok := b.anchors[resolved][fragment]
+			if !ok && target != "" {
+				file, ok = b.files[resolved]
+			}
+			if ok {
+				if fragment != "" {
+					fragment = "#" + fragment
+				}
+				node.Destination = []byte(path.Base(file) + fragment)
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	var buf bytes.Buffer
+	if err := b.md.Renderer().Render(&buf, doc.Source, root); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", doc.Path, err)
+	}
+	return buf.String(), nil
+}
+
+// addImage registers an image for packaging once and returns it
+func (b *epubBook) addImage(source string) (*epubImage, error) {
+	if img, ok := b.images[source]; ok {
+		return img, nil
+	}
+
+	mediaType, ok := epubMediaTypes[strings.ToLower(filepath.Ext(source))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image type %s", filepath.Ext(source))
+	}
+
+	img := &epubImage{
+		ID:        fmt.Sprintf("image-%03d", len(b.imageList)+1),
+		FileName:  "images/" + uniqueFileName(sanitizePackageName(filepath.Base(source)), b.usedNames),
+		MediaType: mediaType,
+		Source:    source,
+	}
+	b.images[source] = img
+	b.imageList = append(b.imageList, img)
+	return img, nil
+}
+
+// sanitizePackageName keeps file names inside EPUB and DOCX packages to
+// characters every reader accepts
+func sanitizePackageName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+// write packages the book into outputPath
+func (b *epubBook) write(outputPath string, options EPUBExportOptions) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputPath, err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimeWriter, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	if _, err := io.WriteString(mimeWriter, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+
+	pkg := epubPackage{
+		ID:       uuid.NewString(),
+		Title:    options.Title,
+		Author:   options.Author,
+		Language: options.Language,
+		Modified: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Chapters: b.chapters,
+		Images:   b.imageList,
+	}
+
+	entries := []struct {
+		name   string
+		render func(w io.Writer) error
+	}{
+		{"META-INF/container.xml", func(w io.Writer) error {
+			_, err := io.WriteString(w, epubContainerXML)
+			return err
+		}},
+		{"OEBPS/content.opf", func(w io.Writer) error { return executeXMLTemplate(w, epubOPFTemplate, pkg) }},
+		{"OEBPS/toc.ncx", func(w io.Writer) error { return executeXMLTemplate(w, epubNCXTemplate, pkg) }},
+		{"OEBPS/nav.xhtml", func(w io.Writer) error { return executeXMLTemplate(w, epubNavTemplate, pkg) }},
+		{"OEBPS/style.css", func(w io.Writer) error {
+			_, err := io.WriteString(w, epubCSS)
+			return err
+		}},
+	}
+
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		if err := entry.render(w); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+
+	for _, chapter := range b.chapters {
+		w, err := zw.Create("OEBPS/" + chapter.FileName)
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		err = executeXMLTemplate(w, epubChapterTemplate, struct {
+			Title    string
+			Language string
+			Body     template.HTML
+		}{chapter.Title, options.Language, template.HTML(chapter.Body)})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", chapter.FileName, err)
+		}
+	}
+
+	for _, img := range b.imageList {
+		data, err := os.ReadFile(img.Source)
+		if err != nil {
+			return fmt.Errorf("failed to read image %s: %w", img.Source, err)
+		}
+		w, err := zw.Create("OEBPS/" + img.FileName)
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", img.FileName, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	return file.Close()
+}
+
+// executeXMLTemplate writes the XML declaration, which html/template would
+// escape, followed by the template output
+func executeXMLTemplate(w io.Writer, tmpl *template.Template, data any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// validateEPUB checks the structure of an EPUB the way epubcheck's container
+// and package checks do: mimetype first and stored, container and package
+// documents present, required metadata, a nav document, every manifest item
+// present and every spine item in the manifest, and well-formed XML throughout
+func validateEPUB(epubPath string) error {
+	zr, err := zip.OpenReader(epubPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", epubPath, err)
+	}
+	defer zr.Close()
+
+	if len(zr.File) == 0 || zr.File[0].Name != "mimetype" {
+		return fmt.Errorf("mimetype must be the first entry")
+	}
+	if zr.File[0].Method != zip.Store {
+		return fmt.Errorf("mimetype must be stored uncompressed")
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	read := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	mimetype, err := read("mimetype")
+	if err != nil {
+		return err
+	}
+	if string(mimetype) != "application/epub+zip" {
+		return fmt.Errorf("mimetype has unexpected content %q", mimetype)
+	}
+
+	containerData, err := read("META-INF/container.xml")
+	if err != nil {
+		return err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(containerData, &container); err != nil {
+		return fmt.Errorf("container.xml is not well-formed: %w", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return fmt.Errorf("container.xml has no rootfile")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	opfData, err := read(opfPath)
+	if err != nil {
+		return err
+	}
+	var opf struct {
+		Version          string `xml:"version,attr"`
+		UniqueIdentifier string `xml:"unique-identifier,attr"`
+		Metadata         struct {
+			Identifiers []struct {
+				ID    string `xml:"id,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"identifier"`
+			Titles    []string `xml:"title"`
+			Languages []string `xml:"language"`
+			Metas     []struct {
+				Property string `xml:"property,attr"`
+				Value    string `xml:",chardata"`
+			} `xml:"meta"`
+		} `xml:"metadata"`
+		Items []struct {
+			ID         string `xml:"id,attr"`
+			Href       string `xml:"href,attr"`
+			MediaType  string `xml:"media-type,attr"`
+			Properties string `xml:"properties,attr"`
+		} `xml:"manifest>item"`
+		Spine struct {
+			Toc      string `xml:"toc,attr"`
+			Itemrefs []struct {
+				IDRef string `xml:"idref,attr"`
+			} `xml:"itemref"`
+		} `xml:"spine"`
+	}
+	if err := xml.Unmarshal(opfData, &opf); err != nil {
+		return fmt.Errorf("%s is not well-formed: %w", opfPath, err)
+	}
+
+	if opf.Version != "3.0" {
+		return fmt.Errorf("package version is %q, want 3.0", opf.Version)
+	}
+	identified := false
+	for _, id := range opf.Metadata.Identifiers {
+		if id.ID == opf.UniqueIdentifier && strings.TrimSpace(id.Value) != "" {
+			identified = true
+		}
+	}
+	if !identified {
+		return fmt.Errorf("unique-identifier %q does not match a dc:identifier", opf.UniqueIdentifier)
+	}
+	if len(opf.Metadata.Titles) == 0 || strings.TrimSpace(opf.Metadata.Titles[0]) == "" {
+		return fmt.Errorf("missing dc:title")
+	}
+	if len(opf.Metadata.Languages) == 0 {
+		return fmt.Errorf("missing dc:language")
+	}
+	modified := false
+	for _, meta := range opf.Metadata.Metas {
+		if meta.Property == "dcterms:modified" {
+			if _, err := time.Parse("2006-01-02T15:04:05Z", meta.Value); err != nil {
+				return fmt.Errorf("dcterms:modified %q is not in CCYY-MM-DDThh:mm:ssZ form", meta.Value)
+			}
+			modified = true
+		}
+	}
+	if !modified {
+		return fmt.Errorf("missing dcterms:modified")
+	}
+
+	opfDir := path.Dir(opfPath)
+	manifest := map[string]bool{}
+	navCount := 0
+	for _, item := range opf.Items {
+		if manifest[item.ID] {
+			return fmt.Errorf("duplicate manifest id %s", item.ID)
+		}
+		manifest[item.ID] = true
+
+		name := path.Join(opfDir, item.Href)
+		data, err := read(name)
+		if err != nil {
+			return fmt.Errorf("manifest item %s: %w", item.ID, err)
+		}
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navCount++
+		}
+		if strings.HasSuffix(item.MediaType, "xml") {
+			if err := checkWellFormedXML(data); err != nil {
+				return fmt.Errorf("%s is not well-formed: %w", name, err)
+			}
+		}
+	}
+	if navCount != 1 {
+		return fmt.Errorf("package must have exactly one nav document, found %d", navCount)
+	}
+
+	if len(opf.Spine.Itemrefs) == 0 {
+		return fmt.Errorf("spine is empty")
+	}
+	if opf.Spine.Toc != "" && !manifest[opf.Spine.Toc] {
+		return fmt.Errorf("spine toc %s is not in the manifest", opf.Spine.Toc)
+	}
+	for _, ref := range opf.Spine.Itemrefs {
+		if !manifest[ref.IDRef] {
+			return fmt.Errorf("spine item %s is not in the manifest", ref.IDRef)
+		}
+	}
+
+	return nil
+}
+
+// checkWellFormedXML parses data to the end, failing on the first XML error
+// or on text outside the single root element
+func checkWellFormedXML(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if roots != 1 {
+				return fmt.Errorf("expected one root element, found %d", roots)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("text outside the root element")
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readEPUBEntries returns the entries of an EPUB in archive order and their contents by name
+func readEPUBEntries(t *testing.T, epubPath string) ([]string, map[string]string) {
+	t.Helper()
+
+	zr, err := zip.OpenReader(epubPath)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", epubPath, err)
+	}
+	defer zr.Close()
+
+	names := []string{}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		names = append(names, f.Name)
+		contents[f.Name] = string(data)
+	}
+	return names, contents
+}
+
+// checkEPUBStructure checks the parts of an EPUB a reading system relies on
+// and returns the spine's chapter files in reading order
+func checkEPUBStructure(t *testing.T, epubPath string) []string {
+	t.Helper()
+
+	if err := validateEPUB(epubPath); err != nil {
+		t.Fatalf("validateEPUB: %v", err)
+	}
+
+	names, contents := readEPUBEntries(t, epubPath)
+	if names[0] != "mimetype" || contents["mimetype"] != "application/epub+zip" {
+		t.Fatalf("first entry is %s with %q, want the mimetype", names[0], contents["mimetype"])
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal([]byte(contents["META-INF/container.xml"]), &container); err != nil {
+		t.Fatalf("container.xml: %v", err)
+	}
+	if len(container.Rootfiles) != 1 || container.Rootfiles[0].FullPath != "OEBPS/content.opf" {
+		t.Fatalf("container.xml rootfiles = %+v", container.Rootfiles)
+	}
+
+	var opf struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine struct {
+			Toc      string `xml:"toc,attr"`
+			Itemrefs []struct {
+				IDRef string `xml:"idref,attr"`
+			} `xml:"itemref"`
+		} `xml:"spine"`
+	}
+	if err := xml.Unmarshal([]byte(contents["OEBPS/content.opf"]), &opf); err != nil {
+		t.Fatalf("content.opf: %v", err)
+	}
+	hrefs := map[string]string{}
+	for _, item := range opf.Items {
+		if _, ok := contents["OEBPS/"+item.Href]; !ok {
+			t.Errorf("manifest item %s points at missing %s", item.ID, item.Href)
+		}
+		hrefs[item.ID] = item.Href
+	}
+	if opf.Spine.Toc != "ncx" || hrefs["ncx"] != "toc.ncx" {
+		t.Errorf("spine toc = %q, ncx href = %q", opf.Spine.Toc, hrefs["ncx"])
+	}
+	spine := []string{}
+	for _, ref := range opf.Spine.Itemrefs {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			t.Fatalf("spine item %s is not in the manifest", ref.IDRef)
+		}
+		spine = append(spine, href)
+	}
+
+	var ncx struct {
+		NavPoints []struct {
+			Label   string `xml:"navLabel>text"`
+			Content struct {
+				Src string `xml:"src,attr"`
+			} `xml:"content"`
+		} `xml:"navMap>navPoint"`
+	}
+	if err := xml.Unmarshal([]byte(contents["OEBPS/toc.ncx"]), &ncx); err != nil {
+		t.Fatalf("toc.ncx: %v", err)
+	}
+	chapters := []string{}
+	for _, href := range spine {
+		if strings.HasPrefix(href, "text/chapter-") {
+			chapters = append(chapters, href)
+		}
+	}
+	if len(ncx.NavPoints) != len(chapters) {
+		t.Fatalf("toc.ncx has %d nav points for %d chapters", len(ncx.NavPoints), len(chapters))
+	}
+	for i, point := range ncx.NavPoints {
+		if point.Content.Src != chapters[i] || point.Label == "" {
+			t.Errorf("nav point %d = %q -> %s, want a label -> %s", i, point.Label, point.Content.Src, chapters[i])
+		}
+	}
+
+	return chapters
+}
+
+func TestExportEPUBNoteSplitsChaptersAndFollowsAnchors(t *testing.T) {
+	dir := t.TempDir()
+	note := filepath.Join(dir, "guide.md")
+	content := strings.Join([]string{
+		"# Intro",
+		"",
+		"<!-- toc -->",
+		"- [Intro](#intro)",
+		"- [Details](#details)",
+		"<!-- tocstop -->",
+		"",
+		"# Details",
+		"",
+		"Back to the [intro](#intro).",
+		"",
+	}, "\n")
+	if err := os.WriteFile(note, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	out, err := app.ExportEPUB(note, EPUBExportOptions{OutputPath: filepath.Join(dir, "guide.epub")})
+	if err != nil {
+		t.Fatalf("ExportEPUB: %v", err)
+	}
+
+	chapters := checkEPUBStructure(t, out)
+	if len(chapters) != 2 {
+		t.Fatalf("got chapters %v, want 2", chapters)
+	}
+
+	_, contents := readEPUBEntries(t, out)
+	first := contents["OEBPS/text/chapter-001.xhtml"]
+	if !strings.Contains(first, `href="chapter-002.xhtml#details"`) {
+		t.Errorf("chapter 1 does not link to the Details heading in chapter 2:\n%s", first)
+	}
+	if !strings.Contains(first, `href="chapter-001.xhtml#intro"`) {
+		t.Errorf("chapter 1 does not link to its own Intro heading:\n%s", first)
+	}
+	second := contents["OEBPS/text/chapter-002.xhtml"]
+	if !strings.Contains(second, `id="details"`) || !strings.Contains(second, `href="chapter-001.xhtml#intro"`) {
+		t.Errorf("chapter 2 is missing the Details heading or its link back:\n%s", second)
+	}
+}
+
+func TestExportEPUBFolder(t *testing.T) {
+	dir := t.TempDir()
+	book := filepath.Join(dir, "book")
+	if err := os.MkdirAll(book, 0755); err != nil {
+		t.Fatal(err)
+	}
+	notes := map[string]string{
+		"b.md": "---\norder: 1\n---\n# First\n\nSee [the second note](a.md#later).\n",
+		"a.md": "---\norder: 2\n---\n# Second\n\n## Later\n\nText.\n",
+	}
+	for name, content := range notes {
+		if err := os.WriteFile(filepath.Join(book, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := NewApp()
+	out, err := app.ExportEPUB(book, EPUBExportOptions{OutputPath: filepath.Join(dir, "book.epub")})
+	if err != nil {
+		t.Fatalf("ExportEPUB: %v", err)
+	}
+
+	chapters := checkEPUBStructure(t, out)
+	if len(chapters) != 2 {
+		t.Fatalf("got chapters %v, want 2", chapters)
+	}
+
+	_, contents := readEPUBEntries(t, out)
+	first := contents["OEBPS/text/chapter-001.xhtml"]
+	if !strings.Contains(first, "First") || !strings.Contains(first, `href="chapter-002.xhtml#later"`) {
+		t.Errorf("chapter 1 should be the ordered-first note linking into chapter 2:\n%s", first)
+	}
+}
+
+func TestValidateEPUBRejectsMimetypeOutOfPlace(t *testing.T) {
+	epubPath := filepath.Join(t.TempDir(), "broken.epub")
+	file, err := os.Create(epubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(file)
+	for _, name := range []string{"META-INF/container.xml", "mimetype"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "application/epub+zip")
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if err := validateEPUB(epubPath); err == nil {
+		t.Fatal("validateEPUB accepted an EPUB whose first entry is not the mimetype")
+	}
+}
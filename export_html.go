@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed themes/*.css
+var themeFS embed.FS
+
+// HTMLExportOptions controls how a note is exported to HTML
+type HTMLExportOptions struct {
+	OutputPath string `json:"outputPath"` // defaults to the note path with an .html extension
+	Theme      string `json:"theme"`      // "light", "dark", "none" or a path to a CSS file
+	CodeStyle  string `json:"codeStyle"`  // chroma style name, defaults to match the theme
+	CopyImages bool   `json:"copyImages"` // copy images into a folder next to the output instead of inlining them
+	Title      string `json:"title"`      // defaults to the note title
+}
+
+var htmlPageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{- if .CSS}}
+<style>
+{{.CSS}}
+</style>
+{{- end}}
+</head>
+<body>
+<article class="markdown-body">
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// htmlPage is the data for htmlPageTemplate
+type htmlPage struct {
+	Title string
+	CSS   template.CSS
+	Body  template.HTML
+}
+
+// ExportHTML renders a note to a single self-contained HTML file and returns its path
+func (a *App) ExportHTML(path string, options HTMLExportOptions) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("path %s is a directory, not a file", path)
+	}
+
+	outputPath := options.OutputPath
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".html"
+	}
+
+	css, err := loadThemeCSS(options.Theme)
+	if err != nil {
+		return "", err
+	}
+
+	codeStyle := options.CodeStyle
+	if codeStyle == "" {
+		codeStyle = defaultCodeStyle(options.Theme)
+	}
+
+	md := newMarkdown(codeStyle)
+	doc, err := parseMarkdownFile(md, path)
+	if err != nil {
+		return "", err
+	}
+
+	// Inline images as data URIs, or copy them into "<name>_files"
+	filesDir := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_files"
+	copied := map[string]string{}
+	usedNames := map[string]bool{}
+	rewriteLocalImages(doc, func(resolved string) (string, error) {
+		if !options.CopyImages {
+			return imageDataURI(resolved)
+		}
+
+		if rel, ok := copied[resolved]; ok {
+			return rel, nil
+		}
+		name := uniqueFileName(filepath.Base(resolved), usedNames)
+		if err := copyFile(resolved, filepath.Join(filesDir, name)); err != nil {
+			return "", err
+		}
+		rel := url.PathEscape(filepath.Base(filesDir)) + "/" + url.PathEscape(name)
+		copied[resolved] = rel
+		return rel, nil
+	})
+
+	body, err := doc.render(md)
+	if err != nil {
+		return "", err
+	}
+
+	title := options.Title
+	if title == "" {
+		title = doc.title()
+	}
+
+	var buf bytes.Buffer
+	err = htmlPageTemplate.Execute(&buf, htmlPage{
+		Title: title,
+		CSS:   template.CSS(css),
+		Body:  template.HTML(body),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render HTML page: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	return outputPath, nil
+}
+
+// loadThemeCSS returns the CSS for a built-in theme name or a CSS file path
+func loadThemeCSS(theme string) (string, error) {
+	switch theme {
+	case "none":
+		return "", nil
+	case "", "light", "dark":
+		if theme == "" {
+			theme = "light"
+		}
+		data, err := themeFS.ReadFile("themes/" + theme + ".css")
+		if err != nil {
+			return "", fmt.Errorf("failed to load theme %s: %w", theme, err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(theme)
+	if err != nil {
+		return "", fmt.Errorf("failed to read theme %s: %w", theme, err)
+	}
+	return string(data), nil
+}
+
+// defaultCodeStyle returns the code highlighting style that suits a theme
+func defaultCodeStyle(theme string) string {
+	if theme == "dark" {
+		return "github-dark"
+	}
+	return "github"
+}
+
+// rewriteLocalImages replaces the destination of every local image in doc with
+// the result of rewrite. Remote and missing images are left untouched.
+func rewriteLocalImages(doc *markdownDocument, rewrite func(resolved string) (string, error)) {
+	baseDir := filepath.Dir(doc.Path)
+
+	for _, img := range doc.images() {
+		dest := string(img.Destination)
+		if dest == "" || isRemoteURL(dest) {
+			continue
+		}
+
+		resolved, err := resolveImagePath(baseDir, dest)
+		if err != nil {
+			fmt.Printf("Warning: Could not resolve image %s in %s: %v\n", dest, doc.Path, err)
+			continue
+		}
+
+		newDest, err := rewrite(resolved)
+		if err != nil {
+			fmt.Printf("Warning: Could not export image %s: %v\n", resolved, err)
+			continue
+		}
+		img.Destination = []byte(newDest)
+	}
+}
+
+// imageDataURI returns the file at path encoded as a data URI
+func imageDataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", path, err)
+	}
+
+	return "data:" + imageMIMEType(path, data) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// imageMIMEType returns the MIME type of an image from its extension, falling back to sniffing
+func imageMIMEType(path string, data []byte) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".svg" {
+		return "image/svg+xml"
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		return strings.SplitN(mimeType, ";", 2)[0]
+	}
+	return http.DetectContentType(data)
+}
+
+// uniqueFileName returns name, or name with a numeric suffix, so that it does
+// not collide with a name already in used, and records the result in used
+func uniqueFileName(name string, used map[string]bool) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// copyFile copies src to dst, creating dst's directory and preserving the modification time
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file info for %s: %w", src, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+)
+
+const (
+	pdfBodyFontSize = 11.0
+	pdfCodeFontSize = 9.0
+	pdfLineHeight   = 5.5
+	pdfCodeLineH    = 4.5
+	pdfListIndent   = 6.0
+	pdfTOCMaxLevel  = 3
+)
+
+// PDFExportOptions controls how a note or folder is exported to PDF
+type PDFExportOptions struct {
+	OutputPath string `json:"outputPath"` // defaults to the note or folder path with a .pdf extension
+	PageSize   string `json:"pageSize"`   // "A4" (default), "Letter" or "Legal"
+	Title      string `json:"title"`      // shown above the table of contents
+	NoTOC      bool   `json:"noToc"`      // skip the table of contents
+}
+
+// pdfHeading is a heading collected while rendering, used for the table of contents
+type pdfHeading struct {
+	Level int
+	Text  string
+	Page  int
+}
+
+// pdfRenderer draws goldmark documents onto a PDF
+type pdfRenderer struct {
+	pdf      *fpdf.Fpdf
+	tr       func(string) string
+	doc      *markdownDocument
+	headings []pdfHeading
+	links    []int // pre-allocated link IDs for headings, in order
+	images   map[string]string
+
+	leftMargin  float64
+	indent      float64
+	bold        bool
+	italic      bool
+	mono        bool
+	levelOffset int
+}
+
+// ExportPDF renders a note, or every note in a folder, to a PDF file and returns its path
+func (a *App) ExportPDF(path string, options PDFExportOptions) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+
+	outputPath := options.OutputPath
+	if outputPath == "" {
+		if info.IsDir() {
+			outputPath = filepath.Clean(path) + ".pdf"
+		} else {
+			outputPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".pdf"
+		}
+	}
+
+	var files []string
+	if info.IsDir() {
+		files, err = collectMarkdownFiles(path, noteSkipDirs(a.workspaceRoot()))
+		if err != nil {
+			return "", err
+		}
+		if len(files) == 0 {
+			return "", fmt.Errorf("no markdown files found in %s", path)
+		}
+	} else {
+		files = []string{path}
+	}
+
+	if err := exportPDF(files, info.IsDir(), outputPath, options); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// exportPDF renders files into outputPath. Folder exports start every note on a
+// new page; notes without a leading title get one so their headings nest under it.
+func exportPDF(files []string, isFolder bool, outputPath string, options PDFExportOptions) error {
+	md := newMarkdown("")
+	docs := make([]*markdownDocument, 0, len(files))
+	for _, file := range files {
+		doc, err := parseMarkdownFile(md, file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	pageSize := options.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+
+	// First pass: lay out the body to learn which page each heading lands on
+	body := newPDFRenderer(pageSize)
+	body.renderDocuments(docs, isFolder)
+	if err := body.pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+
+	tocPages := 0
+	if !options.NoTOC {
+		// Lay out the table of contents alone to learn how many pages it takes
+		toc := newPDFRenderer(pageSize)
+		toc.renderTOC(options.Title, body.headings, 0)
+		tocPages = toc.pdf.PageNo()
+	}
+
+	// Second pass: the real document with the table of contents up front
+	final := newPDFRenderer(pageSize)
+	if !options.NoTOC {
+		for range body.headings {
+			final.links = append(final.links, final.pdf.AddLink())
+		}
+		final.renderTOC(options.Title, body.headings, tocPages)
+	}
+	final.renderDocuments(docs, isFolder)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	if err := final.pdf.OutputFileAndClose(outputPath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	return nil
+}
+
+// collectMarkdownFiles returns the markdown files under dir in path order,
+// skipping hidden entries and the folders in skipDirs
+func collectMarkdownFiles(dir string, skipDirs []string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && slices.Contains(skipDirs, filepath.Clean(path)) {
+			return filepath.SkipDir
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && isMarkdownFile(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// isMarkdownFile reports whether name has a markdown extension
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// newPDFRenderer creates a renderer with page numbers in the footer
+func newPDFRenderer(pageSize string) *pdfRenderer {
+	pdf := fpdf.New("P", "mm", pageSize, "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+
+	r := &pdfRenderer{
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		images: map[string]string{},
+	}
+	r.leftMargin, _, _, _ = pdf.GetMargins()
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	return r
+}
+
+// contentWidth returns the usable width at the current indent
+func (r *pdfRenderer) contentWidth() float64 {
+	pageWidth, _ := r.pdf.GetPageSize()
+	_, _, right, _ := r.pdf.GetMargins()
+	return pageWidth - r.leftMargin - r.indent - right
+}
+
+// setIndent moves the left margin to the current indent
+func (r *pdfRenderer) setIndent(indent float64) {
+	r.indent = indent
+	r.pdf.SetLeftMargin(r.leftMargin + indent)
+	r.pdf.SetX(r.leftMargin + indent)
+}
+
+// applyFont selects the font for the current inline style at size
+func (r *pdfRenderer) applyFont(size float64) {
+	style := ""
+	if r.bold {
+		style += "B"
+	}
+	if r.italic {
+		style += "I"
+	}
+
+	family := "Helvetica"
+	if r.mono {
+		family = "Courier"
+	}
+	r.pdf.SetFont(family, style, size)
+}
+
+// renderTOC draws the table of contents; page numbers are shifted by pageOffset
+func (r *pdfRenderer) renderTOC(title string, headings []pdfHeading, pageOffset int) {
+	pdf := r.pdf
+	pdf.AddPage()
+
+	if title == "" {
+		title = "Contents"
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 12, r.tr(title), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	width := r.contentWidth()
+	for i, heading := range headings {
+		if heading.Level > pdfTOCMaxLevel {
+			continue
+		}
+
+		link := 0
+		if i < len(r.links) {
+			link = r.links[i]
+		}
+
+		indent := float64(heading.Level-1) * pdfListIndent
+		style := ""
+		if heading.Level == 1 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, pdfBodyFontSize)
+		pdf.SetX(r.leftMargin + indent)
+		page := strconv.Itoa(heading.Page + pageOffset)
+		pdf.CellFormat(width-indent-15, 6.5, r.tr(heading.Text), "", 0, "L", false, link, "")
+		pdf.CellFormat(15, 6.5, page, "", 1, "R", false, link, "")
+	}
+}
+
+// renderDocuments draws every document, starting each one on a new page
+func (r *pdfRenderer) renderDocuments(docs []*markdownDocument, isFolder bool) {
+	for _, doc := range docs {
+		r.doc = doc
+		r.pdf.AddPage()
+
+		r.levelOffset = 0
+		if isFolder && !startsWithTitle(doc) {
+			// The note title becomes a top-level heading with its headings nested below
+			r.addHeading(1, doc.title())
+			r.levelOffset = 1
+		}
+
+		r.renderBlocks(doc.Root)
+	}
+}
+
+// startsWithTitle reports whether the document opens with a level 1 heading
+func startsWithTitle(doc *markdownDocument) bool {
+	heading, ok := doc.Root.FirstChild().(*ast.Heading)
+	return ok && heading.Level == 1
+}
+
+// addHeading draws a heading and records it for the table of contents
+func (r *pdfRenderer) addHeading(level int, text string) {
+	pdf := r.pdf
+	sizes := map[int]float64{1: 20, 2: 16, 3: 14, 4: 12}
+	size, ok := sizes[level]
+	if !ok {
+		size = pdfBodyFontSize
+	}
+
+	// Keep the heading with at least a couple of lines of what follows
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+size+2*pdfLineHeight > pageHeight-20 {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(3)
+	y := pdf.GetY()
+	index := len(r.headings)
+	r.headings = append(r.headings, pdfHeading{
+		Level: level,
+		Text:  text,
+		Page:  pdf.PageNo(),
+	})
+	if index < len(r.links) {
+		pdf.SetLink(r.links[index], y, -1)
+	}
+	if level <= pdfTOCMaxLevel {
+		pdf.Bookmark(r.tr(text), level-1, y)
+	}
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", size)
+	pdf.MultiCell(r.contentWidth(), size*0.5, r.tr(text), "", "L", false)
+	pdf.Ln(2)
+}
+
+// renderBlocks draws the block children of n
+func (r *pdfRenderer) renderBlocks(n ast.Node) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		r.renderBlock(child)
+	}
+}
+
+// renderBlock draws a single block node
+func (r *pdfRenderer) renderBlock(n ast.Node) {
+	pdf := r.pdf
+	source := r.doc.Source
+
+	switch node := n.(type) {
+	case *ast.Heading:
+		level := node.Level + r.levelOffset
+		if level > 6 {
+			level = 6
+		}
+		r.addHeading(level, nodeText(node, source))
+
+	case *ast.Paragraph, *ast.TextBlock:
+		r.renderInlines(node, pdfBodyFontSize)
+		pdf.Ln(pdfLineHeight)
+		if _, ok := node.(*ast.Paragraph); ok {
+			pdf.Ln(2)
+		}
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		r.renderCode(node)
+
+	case *ast.Blockquote:
+		pdf.SetTextColor(90, 90, 90)
+		previous := r.indent
+		r.setIndent(previous + pdfListIndent)
+		r.italic = true
+		r.renderBlocks(node)
+		r.italic = false
+		r.setIndent(previous)
+		pdf.SetTextColor(0, 0, 0)
+
+	case *ast.List:
+		r.renderList(node)
+
+	case *ast.ThematicBreak:
+		pdf.Ln(2)
+		y := pdf.GetY()
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Line(r.leftMargin+r.indent, y, r.leftMargin+r.indent+r.contentWidth(), y)
+		pdf.Ln(4)
+
+	case *extast.Table:
+		r.renderTable(node)
+
+	case *ast.HTMLBlock:
+		// Raw HTML has no PDF equivalent; skip it
+
+	default:
+		r.renderBlocks(node)
+	}
+}
+
+// renderList draws a bulleted, numbered or task list
+func (r *pdfRenderer) renderList(list *ast.List) {
+	pdf := r.pdf
+	previous := r.indent
+	number := list.Start
+
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := "-"
+		if list.IsOrdered() {
+			marker = fmt.Sprintf("%d.", number)
+			number++
+		}
+
+		// Task list items carry their checkbox as the first inline
+		if para := item.FirstChild(); para != nil {
+			if box, ok := para.FirstChild().(*extast.TaskCheckBox); ok {
+				marker = "[ ]"
+				if box.IsChecked {
+					marker = "[x]"
+				}
+			}
+		}
+
+		r.setIndent(previous)
+		pdf.SetFont("Helvetica", "", pdfBodyFontSize)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(pdfListIndent+2, pdfLineHeight, marker, "", 0, "L", false, 0, "")
+		r.setIndent(previous + pdfListIndent + 2)
+		pdf.SetX(r.leftMargin + r.indent)
+
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			r.renderBlock(child)
+		}
+	}
+
+	r.setIndent(previous)
+	pdf.Ln(1)
+}
+
+// renderCode draws a code block in monospace on a shaded background
+func (r *pdfRenderer) renderCode(n ast.Node) {
+	pdf := r.pdf
+	source := r.doc.Source
+
+	var buf bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(source))
+	}
+	code := strings.TrimRight(strings.ReplaceAll(buf.String(), "\t", "    "), "\n")
+
+	pdf.SetFont("Courier", "", pdfCodeFontSize)
+	pdf.SetFillColor(245, 245, 245)
+	pdf.SetTextColor(30, 30, 30)
+	pdf.Ln(1)
+	for _, line := range strings.Split(code, "\n") {
+		pdf.SetX(r.leftMargin + r.indent)
+		pdf.MultiCell(r.contentWidth(), pdfCodeLineH, r.tr(line), "", "L", true)
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(3)
+}
+
+// renderTable draws a GFM table with equal column widths
+func (r *pdfRenderer) renderTable(table *extast.Table) {
+	pdf := r.pdf
+	source := r.doc.Source
+
+	var rows [][]string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, r.tr(nodeText(cell, source)))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return
+	}
+
+	columns := len(rows[0])
+	colWidth := r.contentWidth() / float64(columns)
+	lineHeight := 5.0
+	_, pageHeight := pdf.GetPageSize()
+
+	pdf.SetDrawColor(200, 200, 200)
+	for i, cells := range rows {
+		header := i == 0
+		if header {
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.SetFillColor(240, 240, 240)
+		} else {
+			pdf.SetFont("Helvetica", "", 10)
+		}
+
+		// The tallest cell decides the row height
+		maxLines := 1
+		for _, cell := range cells {
+			if n := len(pdf.SplitText(cell, colWidth-2)); n > maxLines {
+				maxLines = n
+			}
+		}
+		rowHeight := float64(maxLines)*lineHeight + 2
+
+		if pdf.GetY()+rowHeight > pageHeight-20 {
+			pdf.AddPage()
+		}
+
+		x := r.leftMargin + r.indent
+		y := pdf.GetY()
+		for c := 0; c < columns; c++ {
+			text := ""
+			if c < len(cells) {
+				text = cells[c]
+			}
+			style := "D"
+			if header {
+				style = "FD"
+			}
+			pdf.Rect(x, y, colWidth, rowHeight, style)
+			pdf.SetXY(x+1, y+1)
+			pdf.MultiCell(colWidth-2, lineHeight, text, "", "L", false)
+			x += colWidth
+		}
+		pdf.SetXY(r.leftMargin+r.indent, y+rowHeight)
+	}
+	pdf.Ln(3)
+}
+
+// renderInlines writes the inline children of n as flowing text
+func (r *pdfRenderer) renderInlines(n ast.Node, size float64) {
+	pdf := r.pdf
+	source := r.doc.Source
+	r.applyFont(size)
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch node := child.(type) {
+		case *ast.Text:
+			pdf.Write(pdfLineHeight, r.tr(string(node.Segment.Value(source))))
+			if node.HardLineBreak() {
+				pdf.Ln(pdfLineHeight)
+			} else if node.SoftLineBreak() {
+				pdf.Write(pdfLineHeight, " ")
+			}
+
+		case *ast.String:
+			pdf.Write(pdfLineHeight, r.tr(string(node.Value)))
+
+		case *ast.CodeSpan:
+			r.mono = true
+			r.applyFont(size - 1)
+			pdf.Write(pdfLineHeight, r.tr(nodeText(node, source)))
+			r.mono = false
+			r.applyFont(size)
+
+		case *ast.Emphasis:
+			wasBold, wasItalic := r.bold, r.italic
+			if node.Level >= 2 {
+				r.bold = true
+			} else {
+				r.italic = true
+			}
+			r.renderInlines(node, size)
+			r.bold, r.italic = wasBold, wasItalic
+			r.applyFont(size)
+
+		case *ast.Link:
+			pdf.SetTextColor(9, 105, 218)
+			pdf.WriteLinkString(pdfLineHeight, r.tr(nodeText(node, source)), string(node.Destination))
+			pdf.SetTextColor(0, 0, 0)
+
+		case *ast.AutoLink:
+			target := string(node.URL(source))
+			pdf.SetTextColor(9, 105, 218)
+			pdf.WriteLinkString(pdfLineHeight, r.tr(string(node.Label(source))), target)
+			pdf.SetTextColor(0, 0, 0)
+
+		case *ast.Image:
+			r.renderImage(node)
+			r.applyFont(size)
+
+		case *extast.TaskCheckBox:
+			// Drawn as the list marker
+
+		case *extast.Strikethrough:
+			r.renderInlines(node, size)
+
+		case *ast.RawHTML:
+			// Raw HTML has no PDF equivalent; skip it
+
+		default:
+			r.renderInlines(node, size)
+		}
+	}
+}
+
+// renderImage draws a local image scaled to the content width. Images that
+// can't be loaded are replaced by their alt text.
+func (r *pdfRenderer) renderImage(img *ast.Image) {
+	pdf := r.pdf
+	alt := nodeText(img, r.doc.Source)
+
+	name, err := r.registerImage(string(img.Destination))
+	if err != nil {
+		fmt.Printf("Warning: Could not embed image %s in %s: %v\n", img.Destination, r.doc.Path, err)
+		pdf.Write(pdfLineHeight, r.tr("["+alt+"]"))
+		return
+	}
+
+	info := pdf.GetImageInfo(name)
+	width, height := info.Width(), info.Height()
+	if maxWidth := r.contentWidth(); width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+height > pageHeight-20 {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(pdfLineHeight)
+	pdf.ImageOptions(name, r.leftMargin+r.indent, pdf.GetY(), width, height, true, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+}
+
+// registerImage loads a local image once, normalised to 8-bit PNG so fpdf
+// accepts any PNG, JPEG or GIF the standard library can decode
+func (r *pdfRenderer) registerImage(ref string) (string, error) {
+	if isRemoteURL(ref) {
+		return "", fmt.Errorf("remote images are not embedded")
+	}
+
+	resolved, err := resolveImagePath(filepath.Dir(r.doc.Path), ref)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := r.images[resolved]; ok {
+		return name, nil
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	decoded, _, err := image.Decode(file)
+	if err != nil {
+		return "", fmt.Errorf("unsupported image format: %w", err)
+	}
+
+	bounds := decoded.Bounds()
+	normalized := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(normalized, normalized.Bounds(), decoded, bounds.Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, normalized); err != nil {
+		return "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	name := fmt.Sprintf("img%d", len(r.images))
+	r.pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	if err := r.pdf.Error(); err != nil {
+		return "", err
+	}
+
+	r.images[resolved] = name
+	return name, nil
+}
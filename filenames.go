@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxFileNameBytes is the longest file or folder name most file systems accept
+const maxFileNameBytes = 255
+
+// FileNameSettings controls how names typed for new and renamed files are cleaned up
+type FileNameSettings struct {
+	// Slugify lowercases names, drops accents and joins words with hyphens,
+	// so "Q3 Plan: Draft" becomes "q3-plan-draft"
+	Slugify bool `json:"slugify"`
+	// AddExtension adds ".md" to new notes typed without an extension, and
+	// keeps the old extension when a file is renamed without one
+	AddExtension bool `json:"addExtension"`
+}
+
+// defaultFileNameSettings returns the settings used until the user changes them
+func defaultFileNameSettings() FileNameSettings {
+	return FileNameSettings{Slugify: false, AddExtension: true}
+}
+
+// GetFileNameSettings returns the file name settings
+func (a *App) GetFileNameSettings() (FileNameSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return FileNameSettings{}, err
+	}
+	return config.FileNames, nil
+}
+
+// SetFileNameSettings saves the file name settings
+func (a *App) SetFileNameSettings(settings FileNameSettings) error {
+	return updateConfig(func(config *Config) error {
+		config.FileNames = settings
+		return nil
+	})
+}
+
+// fileNameSettings loads the settings, falling back to the defaults
+func fileNameSettings() FileNameSettings {
+	config, err := LoadConfig()
+	if err != nil {
+		return defaultFileNameSettings()
+	}
+	return config.FileNames
+}
+
+// windowsReservedNames can't be used as file names on Windows, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// safeRelativeName cleans a name typed by the user into a relative path that
+// is safe on every common file system. Either slash separates folders.
+// Characters Windows forbids become hyphens, names are normalised to Unicode
+// NFC, and "..", absolute paths and reserved device names are rejected. For
+// files the extension of the last segment is kept as is, or defaultExt is
+// added when it has none and AddExtension is set. Dot files such as
+// .markdownsignore keep their leading dot and get no extension.
+func safeRelativeName(name string, settings FileNameSettings, isFile bool, defaultExt string) (string, error) {
+	name = norm.NFC.String(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("name cannot be empty")
+	}
+	if strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("name %s must be relative", name)
+	}
+
+	var segments []string
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' })
+	for i, part := range parts {
+		last := i == len(parts)-1
+		part = strings.TrimSpace(part)
+		if part == "." {
+			continue
+		}
+		if part == ".." {
+			return "", fmt.Errorf("name %s cannot contain \"..\"", name)
+		}
+
+		dotFile := strings.HasPrefix(part, ".") && !strings.Contains(part[1:], ".")
+		ext := ""
+		if last && isFile {
+			ext = fileExtension(part)
+			part = strings.TrimSuffix(part, ext)
+			if ext == "" && settings.AddExtension && !dotFile {
+				ext = defaultExt
+			}
+			if settings.Slugify {
+				ext = strings.ToLower(ext)
+			}
+		}
+
+		segment := cleanNameSegment(part, settings.Slugify)
+		if segment == "" {
+			return "", fmt.Errorf("name %s has no usable characters", name)
+		}
+		if dotFile && !strings.HasPrefix(segment, ".") {
+			segment = "." + segment
+		}
+		segment += ext
+		if isReservedName(segment) {
+			return "", fmt.Errorf("%s is a reserved name", segment)
+		}
+		if len(segment) > maxFileNameBytes {
+			return "", fmt.Errorf("name %s is longer than %d bytes", segment, maxFileNameBytes)
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("name %s has no usable characters", name)
+	}
+
+	return filepath.Join(segments...), nil
+}
+
+// isReservedName reports whether a file or folder name is reserved on Windows.
+// Windows ignores everything from the first dot, so "nul.txt" and
+// "aux.tar.gz" are as reserved as "nul" and "aux".
+func isReservedName(name string) bool {
+	stem, _, _ := strings.Cut(name, ".")
+	return windowsReservedNames[strings.ToLower(strings.TrimRight(stem, " "))]
+}
+
+// fileExtension returns the extension of name if it looks like one: a few
+// letters or digits after the last dot, not all digits, so "v1.2" and
+// "Meeting 3.5 notes" have none
+func fileExtension(name string) string {
+	ext := filepath.Ext(name)
+	if len(ext) < 2 || len(ext) > 9 || ext == name {
+		return ""
+	}
+	hasLetter := false
+	for _, r := range ext[1:] {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+		default:
+			return ""
+		}
+	}
+	if !hasLetter {
+		return ""
+	}
+	return ext
+}
+
+// cleanNameSegment replaces the characters that break on some file system in
+// one path segment, and slugifies it when asked
+func cleanNameSegment(segment string, slugify bool) string {
+	if slugify {
+		return slugifyName(segment)
+	}
+
+	var b strings.Builder
+	for _, r := range segment {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			// Control characters are dropped
+		case strings.ContainsRune(`<>:"|?*`, r):
+			b.WriteByte('-')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	// Windows drops trailing dots and spaces, so names that differ only there collide
+	return strings.TrimRight(strings.TrimSpace(b.String()), ". ")
+}
+
+// slugifyName lowercases segment, strips accents and joins the remaining
+// letters and digits with single hyphens
+func slugifyName(segment string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(strings.ToLower(segment)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accents left by NFD
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	return norm.NFC.String(b.String())
+}
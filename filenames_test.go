@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeRelativeNameRejectsReservedNames(t *testing.T) {
+	settings := defaultFileNameSettings()
+	for _, tc := range []struct {
+		name   string
+		isFile bool
+	}{
+		{"con", true},
+		{"aux.tar.gz", true},
+		{"NUL.md", true},
+		{"notes/com1.txt/today", true},
+		{"nul.txt", false},
+		{"con.md/sub", false},
+		{"lpt9 .notes", false},
+	} {
+		if got, err := safeRelativeName(tc.name, settings, tc.isFile, ".md"); err == nil {
+			t.Errorf("safeRelativeName(%q, isFile=%v) = %q, want a reserved name error", tc.name, tc.isFile, got)
+		}
+	}
+
+	for _, name := range []string{"console.md", "auxiliary", "nullable.tar.gz", "com10"} {
+		if _, err := safeRelativeName(name, settings, true, ".md"); err != nil {
+			t.Errorf("safeRelativeName(%q) = %v, want it accepted", name, err)
+		}
+	}
+}
+
+func TestSafeRelativeNameKeepsDotFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		slugify bool
+		want    string
+	}{
+		{".markdownsignore", false, ".markdownsignore"},
+		{"docs/.markdownstemplate", false, "docs/.markdownstemplate"},
+		{".markdownsignore", true, ".markdownsignore"},
+		{".Hidden Notes", true, ".hidden-notes"},
+		{".notes.txt", false, ".notes.txt"},
+		{"notes", false, "notes.md"},
+	} {
+		settings := FileNameSettings{Slugify: tc.slugify, AddExtension: true}
+		got, err := safeRelativeName(tc.name, settings, true, ".md")
+		if err != nil || got != filepath.FromSlash(tc.want) {
+			t.Errorf("safeRelativeName(%q, slugify=%v) = %q, %v, want %q", tc.name, tc.slugify, got, err, tc.want)
+		}
+	}
+}
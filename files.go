@@ -18,6 +18,7 @@ type FileEntry struct {
 	IsDirectory bool      `json:"isDirectory"`
 	Size        int64     `json:"size"`
 	ModTime     time.Time `json:"modTime"`
+	GitStatus   string    `json:"gitStatus,omitempty"` // "modified", "staged" or "untracked" in a git workspace
 }
 
 // OpenFileResponse represents the state after opening a file or directory
@@ -26,6 +27,7 @@ type CurrentFilesState struct {
 	CurrentFile *FileEntry `json:"currentFile,omitempty"`
 	FileInfo    *FileEntry `json:"fileInfo,omitempty"`
 	ContentHash string     `json:"contentHash,omitempty"`
+	Tabs        []Tab      `json:"tabs,omitempty"`
 }
 
 func (a *App) ListFiles(path string) ([]FileEntry, error) {
@@ -46,6 +48,15 @@ func (a *App) ListFiles(path string) ([]FileEntry, error) {
 		showHidden = false
 	}
 
+	// Git status is only shown for a repository at the workspace root
+	var statuses map[string]string
+	if root := a.gitRoot(); root != "" && isSameOrChildPath(dirPath, root) {
+		statuses, err = gitStatuses(root)
+		if err != nil {
+			fmt.Printf("Warning: Could not read git status: %v\n", err)
+		}
+	}
+
 	var fileEntries []FileEntry
 	for _, entry := range entries {
 		// Filter hidden files based on config
@@ -67,12 +78,14 @@ func (a *App) ListFiles(path string) ([]FileEntry, error) {
 			}
 		}
 
+		entryPath := filepath.Join(dirPath, entry.Name())
 		fileEntries = append(fileEntries, FileEntry{
 			Name:        entry.Name(),
-			Path:        filepath.Join(dirPath, entry.Name()),
+			Path:        entryPath,
 			IsDirectory: entry.IsDir(),
 			Size:        info.Size(),
 			ModTime:     info.ModTime(),
+			GitStatus:   statuses[entryPath],
 		})
 	}
 	return fileEntries, nil
@@ -86,34 +99,32 @@ func (a *App) OpenFile(path string) (CurrentFilesState, error) {
 		return CurrentFilesState{}, fmt.Errorf("failed to get file info for %s: %w", path, err)
 	}
 
-	// Load config to update last opened directory/file
-	config, err := LoadConfig()
-	if err != nil {
-		// If config fails to load, continue without saving
-		fmt.Printf("Warning: Could not load config: %v\n", err)
-		config = DefaultConfig()
-	}
-
 	if info.IsDir() {
 		a.currentDir = path
 		// Update window title when directory changes
 		a.UpdateWindowTitleWithCurrentDir()
 		// Save last opened directory to config
-		config.LastOpenedDirectory = path
+		a.saveLastOpenedDirectory(path)
 	} else {
-		a.currentFile = path
-		// Save last opened file to config
-		config.LastOpenedFile = path
-		// Also add to recent files
-		a.AddRecentFile(path)
+		// Open the file in a tab, which also saves it as the last opened file
+		if _, err := a.OpenTab(path); err != nil {
+			return CurrentFilesState{}, err
+		}
 	}
 
-	// Save config
-	if err := SaveConfig(config); err != nil {
+	return a.GetCurrentFilesState(), nil
+}
+
+// saveLastOpenedDirectory remembers dir as the last opened directory,
+// warning rather than failing when the config can't be updated
+func (a *App) saveLastOpenedDirectory(dir string) {
+	err := updateConfig(func(config *Config) error {
+		config.LastOpenedDirectory = dir
+		return nil
+	})
+	if err != nil {
 		fmt.Printf("Warning: Could not save config: %v\n", err)
 	}
-
-	return a.GetCurrentFilesState(), nil
 }
 
 // GoUp navigates one directory up from the current directory
@@ -135,15 +146,7 @@ func (a *App) GoUp() (*CurrentFilesState, error) {
 	a.UpdateWindowTitleWithCurrentDir()
 
 	// Save last opened directory to config
-	config, err := LoadConfig()
-	if err != nil {
-		fmt.Printf("Warning: Could not load config: %v\n", err)
-		config = DefaultConfig()
-	}
-	config.LastOpenedDirectory = parentDir
-	if err := SaveConfig(config); err != nil {
-		fmt.Printf("Warning: Could not save config: %v\n", err)
-	}
+	a.saveLastOpenedDirectory(parentDir)
 
 	// Get parent directory info
 	dirInfo, err := os.Stat(parentDir)
@@ -190,18 +193,22 @@ func (a *App) DeleteFile(path string) error {
 		if a.currentDir == path {
 			a.currentDir = filepath.Dir(path)
 		}
-	} else {
-		// If deleting current file, clear current file state
-		if a.currentFile == path {
-			a.currentFile = ""
-			a.currentFileContent = ""
-		}
 	}
 
 	err = os.RemoveAll(path) // RemoveAll handles both files and directories recursively
 	if err != nil {
 		return fmt.Errorf("failed to delete %s: %w", path, err)
 	}
+
+	// Close tabs for the deleted file or anything inside the deleted directory,
+	// which also clears the current file if it was one of them
+	a.closeTabsUnder(path)
+
+	// Forget view state for anything that was deleted
+	if err := a.viewStates.removeUnder(path); err != nil {
+		fmt.Printf("Warning: Could not update view state: %v\n", err)
+	}
+
 	return nil
 }
 
@@ -273,20 +280,24 @@ func (a *App) GetContentHash(content string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// ClearCurrentFile clears the current file state and returns the updated state
-func (a *App) ClearCurrentFile() CurrentFilesState {
-	a.currentFile = ""
-	a.currentFileContent = ""
+// hashContent returns the SHA-256 hash of content without logging it
+func hashContent(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
 
-	// Clear last opened file from config
-	config, err := LoadConfig()
-	if err != nil {
-		fmt.Printf("Warning: Could not load config: %v\n", err)
-		config = DefaultConfig()
-	}
-	config.LastOpenedFile = ""
-	if err := SaveConfig(config); err != nil {
-		fmt.Printf("Warning: Could not save config: %v\n", err)
+// ClearCurrentFile closes the current file's tab and returns the updated state.
+// The next open tab, if any, becomes the current file.
+func (a *App) ClearCurrentFile() CurrentFilesState {
+	if a.currentFile != "" {
+		if _, err := a.CloseTab(a.currentFile); err != nil {
+			// Not open in a tab, just clear it
+			a.currentFile = ""
+			a.currentFileContent = ""
+			if err := a.saveTabs(); err != nil {
+				fmt.Printf("Warning: Could not save tabs: %v\n", err)
+			}
+		}
 	}
 
 	return a.GetCurrentFilesState()
@@ -331,93 +342,156 @@ func (a *App) GetCurrentFilesState() CurrentFilesState {
 		}
 	}
 
+	// Include open tabs so the frontend can render them
+	state.Tabs = a.ListTabs()
+
 	return state
 }
 
 // CreateFile creates a new file with the given name in the current directory
-func (a *App) CreateFile(name string) error {
+// and returns its path. The name is cleaned up for every file system, may
+// include folders, which are created as needed, and gets ".md" when it has
+// no extension. The file starts from the folder's default template, if it
+// has one.
+func (a *App) CreateFile(name string) (string, error) {
 	if name == "" {
-		return fmt.Errorf("file name cannot be empty")
+		return "", fmt.Errorf("file name cannot be empty")
 	}
 
-	filePath := filepath.Join(a.currentDir, name)
+	result, err := a.createNoteInDir(a.currentDir, name, "", nil)
+	return result.Path, err
+}
+
+// createFileWithContent creates a new file named name in dir and writes content
+// to it. It fails if the file already exists and returns the new file's path.
+func createFileWithContent(dir string, name string, content string) (string, error) {
+	filePath := filepath.Join(dir, name)
 
 	// Check if file already exists
 	if _, err := os.Stat(filePath); err == nil {
-		return fmt.Errorf("file %s already exists", name)
+		return "", fmt.Errorf("file %s already exists", name)
 	}
 
 	// Create the file
-	file, err := os.Create(filePath)
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", name, err)
+		return "", fmt.Errorf("failed to create file %s: %w", name, err)
 	}
 	defer file.Close()
 
-	return nil
+	if _, err := file.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %w", name, err)
+	}
+
+	return filePath, nil
 }
 
-// CreateDir creates a new directory with the given name in the current directory
-func (a *App) CreateDir(name string) error {
+// CreateDir creates a new directory with the given name in the current
+// directory, along with any missing parents, and returns its path. The name
+// is cleaned up the same way as in CreateFile.
+func (a *App) CreateDir(name string) (string, error) {
 	if name == "" {
-		return fmt.Errorf("directory name cannot be empty")
+		return "", fmt.Errorf("directory name cannot be empty")
 	}
 
-	dirPath := filepath.Join(a.currentDir, name)
+	rel, err := safeRelativeName(name, fileNameSettings(), false, "")
+	if err != nil {
+		return "", err
+	}
+	dirPath := filepath.Join(a.currentDir, rel)
 
 	// Check if directory already exists
 	if _, err := os.Stat(dirPath); err == nil {
-		return fmt.Errorf("directory %s already exists", name)
+		return "", fmt.Errorf("directory %s already exists", rel)
 	}
 
 	// Create the directory
-	err := os.Mkdir(dirPath, 0755)
+	err = os.MkdirAll(dirPath, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", name, err)
+		return "", fmt.Errorf("failed to create directory %s: %w", rel, err)
 	}
 
-	return nil
+	return dirPath, nil
 }
 
-// RenameFile renames a file or directory
-func (a *App) RenameFile(oldPath string, newName string) error {
+// RenameFile renames a file or directory and returns its new path. The new
+// name is cleaned up the same way as in CreateFile, keeps the old extension
+// when it has none, and may move the file into new subfolders of its directory.
+func (a *App) RenameFile(oldPath string, newName string) (string, error) {
 	if newName == "" {
-		return fmt.Errorf("new name cannot be empty")
+		return "", fmt.Errorf("new name cannot be empty")
 	}
 
 	// Check if old path exists
 	info, err := os.Stat(oldPath)
 	if err != nil {
-		return fmt.Errorf("failed to get file info for %s: %w", oldPath, err)
+		return "", fmt.Errorf("failed to get file info for %s: %w", oldPath, err)
+	}
+
+	rel, err := safeRelativeName(newName, fileNameSettings(), !info.IsDir(), filepath.Ext(oldPath))
+	if err != nil {
+		return "", err
 	}
 
 	// Build the new path (same directory, new name)
 	dir := filepath.Dir(oldPath)
-	newPath := filepath.Join(dir, newName)
+	newPath := filepath.Join(dir, rel)
+	if newPath == oldPath {
+		return newPath, nil
+	}
+
+	// Check if new path already exists. On case-insensitive file systems a
+	// change of case finds the file itself, which is fine.
+	if existing, err := os.Stat(newPath); err == nil && !os.SameFile(info, existing) {
+		return "", fmt.Errorf("a file or directory named %s already exists", rel)
+	}
 
-	// Check if new path already exists
-	if _, err := os.Stat(newPath); err == nil {
-		return fmt.Errorf("a file or directory named %s already exists", newName)
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", newPath, err)
 	}
 
 	// Rename the file/directory
 	err = os.Rename(oldPath, newPath)
 	if err != nil {
-		return fmt.Errorf("failed to rename %s to %s: %w", oldPath, newName, err)
+		return "", fmt.Errorf("failed to rename %s to %s: %w", oldPath, rel, err)
 	}
 
-	// Update current file/dir state if necessary
+	// Update current dir state if necessary
 	if info.IsDir() && a.currentDir == oldPath {
 		a.currentDir = newPath
-	} else if !info.IsDir() && a.currentFile == oldPath {
-		a.currentFile = newPath
 	}
 
-	return nil
+	// Point open tabs, including the current file, at the new path
+	a.renameTabsUnder(oldPath, newPath)
+
+	// Carry view state over to the new path
+	if err := a.viewStates.renameUnder(oldPath, newPath); err != nil {
+		fmt.Printf("Warning: Could not update view state: %v\n", err)
+	}
+
+	// Keep recent files pointing at the renamed file
+	if err := renameRecentFiles(oldPath, newPath); err != nil {
+		fmt.Printf("Warning: Could not update recent files: %v\n", err)
+	}
+
+	return newPath, nil
 }
 
 // SaveFile saves content to the specified file
 func (a *App) SaveFile(path string, content string) error {
+	if err := a.writeNote(path, content); err != nil {
+		return err
+	}
+
+	a.autoCommit(path)
+
+	return nil
+}
+
+// writeNote saves content to an existing file like SaveFile but without
+// auto-committing it, for operations that write many files and commit them
+// together at the end
+func (a *App) writeNote(path string, content string) error {
 	if path == "" {
 		return fmt.Errorf("file path cannot be empty")
 	}
@@ -444,5 +518,8 @@ func (a *App) SaveFile(path string, content string) error {
 		a.currentFileContent = content
 	}
 
+	// The tab now matches what is on disk
+	a.markTabSaved(path, content)
+
 	return nil
 }
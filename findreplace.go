@@ -0,0 +1,438 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Find and replace match modes
+const (
+	findModeLiteral = "literal"
+	findModeWord    = "word"  // the literal text as a whole word
+	findModeRegex   = "regex" // RE2 syntax; $1 and ${name} in the replacement expand capture groups
+)
+
+// maxReplaceSnapshots is how many applied replacements can still be undone
+const maxReplaceSnapshots = 20
+
+// FindReplaceOptions controls what FindReplace matches and where
+type FindReplaceOptions struct {
+	Mode          string `json:"mode"` // "literal" (default), "word" or "regex"
+	CaseSensitive bool   `json:"caseSensitive"`
+	Folder        string `json:"folder"` // only notes under this folder, absolute or relative to the workspace root; "" for all
+	// Glob only includes notes matching it, relative to the workspace root.
+	// "*" stays within a folder and "**" spans folders; a pattern without a
+	// "/" is matched against file names.
+	Glob  string `json:"glob"`
+	Apply bool   `json:"apply"` // write the changes instead of only previewing them
+}
+
+// FindReplaceChange is one changed line
+type FindReplaceChange struct {
+	Line   int    `json:"line"` // 1-based
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FindReplaceFile is the changes to one note
+type FindReplaceFile struct {
+	Path    string              `json:"path"`
+	Matches int                 `json:"matches"`
+	Changes []FindReplaceChange `json:"changes"`
+}
+
+// FindReplaceResult is a preview of a replacement, or what was changed once applied
+type FindReplaceResult struct {
+	Files      []FindReplaceFile `json:"files"`
+	Matches    int               `json:"matches"`
+	Applied    bool              `json:"applied"`
+	SnapshotID string            `json:"snapshotId"` // pass to UndoFindReplace; "" until applied
+	// Skipped lists notes with unsaved edits in a tab. They are left out so the
+	// replacement does not overwrite those edits; save them and run it again.
+	Skipped []string `json:"skipped"`
+}
+
+// replaceSnapshot holds what an applied replacement changed so it can be undone
+type replaceSnapshot struct {
+	ID          string                `json:"id"`
+	Created     time.Time             `json:"created"`
+	Description string                `json:"description"`
+	Files       []replaceSnapshotFile `json:"files"`
+}
+
+// replaceSnapshotFile is a note's content before a replacement and the hash of its content after
+type replaceSnapshotFile struct {
+	Path      string `json:"path"`
+	Before    string `json:"before"`
+	AfterHash string `json:"afterHash"`
+}
+
+// replaceMatcher finds and replaces query in single lines
+type replaceMatcher struct {
+	re          *regexp.Regexp
+	replacement string
+	wholeWord   bool
+	expand      bool // expand capture groups in the replacement
+}
+
+// newReplaceMatcher compiles query for the mode in options
+func newReplaceMatcher(query string, replacement string, options FindReplaceOptions) (*replaceMatcher, error) {
+	if query == "" {
+		return nil, fmt.Errorf("search text cannot be empty")
+	}
+
+	m := &replaceMatcher{replacement: replacement}
+	pattern := regexp.QuoteMeta(query)
+	switch options.Mode {
+	case "", findModeLiteral:
+	case findModeWord:
+		m.wholeWord = true
+	case findModeRegex:
+		pattern = query
+		m.expand = true
+	default:
+		return nil, fmt.Errorf("unknown match mode %q", options.Mode)
+	}
+	if !options.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	m.re = re
+	return m, nil
+}
+
+// replaceLine returns line with every match replaced and the number of matches
+func (m *replaceMatcher) replaceLine(line string) (string, int) {
+	matches := m.re.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line, 0
+	}
+
+	var b strings.Builder
+	count := 0
+	last := 0
+	for _, match := range matches {
+		if m.wholeWord && !isWholeWord(line, match[0], match[1]) {
+			continue
+		}
+		b.WriteString(line[last:match[0]])
+		if m.expand {
+			b.Write(m.re.ExpandString(nil, m.replacement, line, match))
+		} else {
+			b.WriteString(m.replacement)
+		}
+		last = match[1]
+		count++
+	}
+	if count == 0 {
+		return line, 0
+	}
+	b.WriteString(line[last:])
+	return b.String(), count
+}
+
+// isWholeWord reports whether line[start:end] isn't part of a longer word.
+// Unlike \b this treats letters and digits of every script as word characters.
+func isWholeWord(line string, start int, end int) bool {
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+	}
+	if before, _ := utf8.DecodeLastRuneInString(line[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(line[end:]); end < len(line) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+// replaceContent applies the matcher to each line of content. Matches don't
+// span lines, and line endings are kept as they are.
+func (m *replaceMatcher) replaceContent(content string) (string, int, []FindReplaceChange) {
+	lines := strings.Split(content, "\n")
+	total := 0
+	changes := []FindReplaceChange{}
+	for i, line := range lines {
+		text := strings.TrimSuffix(line, "\r")
+		replaced, count := m.replaceLine(text)
+		if count == 0 {
+			continue
+		}
+		total += count
+		lines[i] = replaced + line[len(text):]
+		if replaced != text {
+			changes = append(changes, FindReplaceChange{Line: i + 1, Before: text, After: replaced})
+		}
+	}
+	return strings.Join(lines, "\n"), total, changes
+}
+
+// matchGlob reports whether the slash path rel matches pattern. "**" matches
+// any number of folders and a pattern without a "/" matches the file name.
+func matchGlob(pattern string, rel string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(rel))
+		return matched
+	}
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchGlobSegments matches path segments against pattern segments
+func matchGlobSegments(patterns []string, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlobSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+		return false
+	}
+	return matchGlobSegments(patterns[1:], segments[1:])
+}
+
+// FindReplace finds query in the workspace notes in scope and returns every
+// line that would change. With options.Apply the changes are saved, after
+// snapshotting the notes so UndoFindReplace can restore them all at once.
+func (a *App) FindReplace(query string, replacement string, options FindReplaceOptions) (FindReplaceResult, error) {
+	result := FindReplaceResult{Files: []FindReplaceFile{}, Skipped: []string{}}
+
+	root := a.workspaceRoot()
+	if root == "" {
+		return result, fmt.Errorf("no workspace is open")
+	}
+	folder := ""
+	if options.Folder != "" {
+		folder = resolveInWorkspace(root, options.Folder)
+	}
+	matcher, err := newReplaceMatcher(query, replacement, options)
+	if err != nil {
+		return result, err
+	}
+	if options.Glob != "" {
+		if _, err := path.Match(strings.ReplaceAll(options.Glob, "**", "*"), ""); err != nil {
+			return result, fmt.Errorf("invalid glob %q: %w", options.Glob, err)
+		}
+	}
+
+	updated := map[string]string{}
+	snapshot := replaceSnapshot{
+		ID:          uuid.NewString(),
+		Created:     time.Now(),
+		Description: fmt.Sprintf("Replace %q with %q", query, replacement),
+	}
+	err = walkNotes(root, nil, func(p string, rel string, d fs.DirEntry) error {
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+		if folder != "" && !isSameOrChildPath(p, folder) {
+			return nil
+		}
+		if options.Glob != "" && !matchGlob(options.Glob, filepath.ToSlash(rel)) {
+			return nil
+		}
+		if a.hasUnsavedEdits(p) {
+			result.Skipped = append(result.Skipped, p)
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", p, err)
+		}
+		content, count, changes := matcher.replaceContent(string(data))
+		if count == 0 {
+			return nil
+		}
+
+		result.Files = append(result.Files, FindReplaceFile{Path: p, Matches: count, Changes: changes})
+		result.Matches += count
+		if content != string(data) {
+			updated[p] = content
+			snapshot.Files = append(snapshot.Files, replaceSnapshotFile{
+				Path:      p,
+				Before:    string(data),
+				AfterHash: hashContent(content),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to search workspace %s: %w", root, err)
+	}
+
+	if !options.Apply || len(updated) == 0 {
+		return result, nil
+	}
+
+	// Snapshot before writing anything so a failure part way can be undone too
+	if err := saveReplaceSnapshot(snapshot); err != nil {
+		return result, err
+	}
+	result.SnapshotID = snapshot.ID
+	// Every changed note goes into one commit, even if writing stops part way
+	var written []string
+	defer func() {
+		a.autoCommit(written...)
+		a.notifyNotesChanged(written...)
+	}()
+	for _, file := range snapshot.Files {
+		if err := a.writeNote(file.Path, updated[file.Path]); err != nil {
+			return result, fmt.Errorf("replacement stopped part way, undo it with the snapshot: %w", err)
+		}
+		written = append(written, file.Path)
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// UndoFindReplace restores every note changed by an applied replacement and
+// returns their paths. Nothing is restored if any of them changed since.
+func (a *App) UndoFindReplace(snapshotID string) ([]string, error) {
+	snapshotPath, err := replaceSnapshotPath(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("replacement %s can no longer be undone: %w", snapshotID, err)
+	}
+	var snapshot replaceSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", snapshotID, err)
+	}
+
+	// A note edited since, on disk or in an unsaved tab, would lose that edit,
+	// so check them all first. Notes the replacement never got to are still
+	// as they were.
+	var restore []replaceSnapshotFile
+	var changed []string
+	for _, file := range snapshot.Files {
+		current, err := os.ReadFile(file.Path)
+		if err != nil {
+			changed = append(changed, file.Path)
+			continue
+		}
+		switch hashContent(string(current)) {
+		case file.AfterHash:
+			if a.hasUnsavedEdits(file.Path) {
+				changed = append(changed, file.Path)
+				continue
+			}
+			restore = append(restore, file)
+		case hashContent(file.Before):
+		default:
+			changed = append(changed, file.Path)
+		}
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("cannot undo the replacement, these notes changed since: %s", strings.Join(changed, ", "))
+	}
+
+	restored := []string{}
+	defer func() {
+		a.autoCommit(restored...)
+		a.notifyNotesChanged(restored...)
+	}()
+	for _, file := range restore {
+		if err := a.writeNote(file.Path, file.Before); err != nil {
+			return restored, err
+		}
+		restored = append(restored, file.Path)
+	}
+
+	if err := os.Remove(snapshotPath); err != nil {
+		fmt.Printf("Warning: Could not remove snapshot %s: %v\n", snapshotPath, err)
+	}
+	return restored, nil
+}
+
+// replaceSnapshotDir returns the folder holding find and replace snapshots
+func replaceSnapshotDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "replace-snapshots"), nil
+}
+
+// replaceSnapshotPath returns the file of a snapshot, rejecting IDs that aren't UUIDs
+func replaceSnapshotPath(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid snapshot ID %q", id)
+	}
+	dir, err := replaceSnapshotDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// saveReplaceSnapshot writes a snapshot and drops the oldest beyond maxReplaceSnapshots
+func saveReplaceSnapshot(snapshot replaceSnapshot) error {
+	snapshotPath, err := replaceSnapshotPath(snapshot.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(snapshotPath), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(snapshotPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(snapshotPath))
+	if err != nil {
+		return nil
+	}
+	type snapshotFile struct {
+		path     string
+		modified time.Time
+	}
+	var files []snapshotFile
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		files = append(files, snapshotFile{filepath.Join(filepath.Dir(snapshotPath), entry.Name()), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modified.After(files[j].modified)
+	})
+	for i := maxReplaceSnapshots; i < len(files); i++ {
+		os.Remove(files[i].path)
+	}
+	return nil
+}
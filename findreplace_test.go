@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindReplaceFolderAndUnsavedTabs(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	inside := filepath.Join(root, "docs", "a.md")
+	edited := filepath.Join(root, "docs", "b.md")
+	outside := filepath.Join(root, "c.md")
+	for _, path := range []string{inside, edited, outside} {
+		writeTestFile(t, path, "- [ ] old\n")
+	}
+
+	app := NewApp()
+	app.workspacePath = root
+	if _, err := app.OpenTab(edited); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTabContent(edited, "- [ ] old, unsaved\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := app.FindReplace("old", "new", FindReplaceOptions{Folder: "docs", Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != inside {
+		t.Fatalf("changed %+v, want only %s", result.Files, inside)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != edited {
+		t.Fatalf("skipped %q, want the note with unsaved edits", result.Skipped)
+	}
+	for path, want := range map[string]string{inside: "- [ ] new\n", edited: "- [ ] old\n", outside: "- [ ] old\n"} {
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("%s reads %q, want %q", path, data, want)
+		}
+	}
+	if !app.hasUnsavedEdits(edited) {
+		t.Error("the skipped tab lost its unsaved edits")
+	}
+}
+
+func TestFindReplacePreviewLeavesNotes(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	note := filepath.Join(root, "note.md")
+	writeTestFile(t, note, "old and old\r\nkeep\r\nold\r\n")
+
+	app := NewApp()
+	app.workspacePath = root
+	result, err := app.FindReplace("old", "new", FindReplaceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Applied || result.SnapshotID != "" || result.Matches != 3 {
+		t.Errorf("preview = %+v, want 3 matches and nothing applied", result)
+	}
+	want := []FindReplaceChange{
+		{Line: 1, Before: "old and old", After: "new and new"},
+		{Line: 3, Before: "old", After: "new"},
+	}
+	if len(result.Files) != 1 || !reflect.DeepEqual(result.Files[0].Changes, want) {
+		t.Errorf("preview changes %+v, want %+v", result.Files, want)
+	}
+	if data, _ := os.ReadFile(note); string(data) != "old and old\r\nkeep\r\nold\r\n" {
+		t.Errorf("preview rewrote the note to %q", data)
+	}
+}
+
+func TestReplaceLine(t *testing.T) {
+	for _, tc := range []struct {
+		query, replacement string
+		options            FindReplaceOptions
+		line, want         string
+		count              int
+	}{
+		{"cat", "dog", FindReplaceOptions{}, "Cat cat CAT", "dog dog dog", 3},
+		{"cat", "dog", FindReplaceOptions{CaseSensitive: true}, "Cat cat CAT", "Cat dog CAT", 1},
+		{"a.b", "x", FindReplaceOptions{}, "a.b axb", "x axb", 1},
+		{"$1", "x", FindReplaceOptions{}, "cost $1", "cost x", 1},
+		{"cat", "dog", FindReplaceOptions{Mode: findModeWord}, "cat catalog bobcat cat_1 cat.", "dog catalog bobcat cat_1 dog.", 2},
+		{"über", "over", FindReplaceOptions{Mode: findModeWord}, "über überall", "over überall", 1},
+		{"catalog", "x", FindReplaceOptions{Mode: findModeWord}, "cat", "cat", 0},
+		{`(\w+)@(\w+)`, "$2 at $1", FindReplaceOptions{Mode: findModeRegex}, "ann@home", "home at ann", 1},
+		{`(?P<year>\d{4})-(?P<month>\d{2})`, "${month}/${year}", FindReplaceOptions{Mode: findModeRegex}, "2024-03 and 1999-12", "03/2024 and 12/1999", 2},
+		{`todo`, "DONE", FindReplaceOptions{Mode: findModeRegex}, "TODO todo", "DONE DONE", 2},
+		{`todo`, "$0!", FindReplaceOptions{Mode: findModeRegex, CaseSensitive: true}, "TODO todo", "TODO todo!", 1},
+		{"a", "$1", FindReplaceOptions{}, "a", "$1", 1},
+	} {
+		m, err := newReplaceMatcher(tc.query, tc.replacement, tc.options)
+		if err != nil {
+			t.Errorf("newReplaceMatcher(%q, %+v) failed: %v", tc.query, tc.options, err)
+			continue
+		}
+		got, count := m.replaceLine(tc.line)
+		if got != tc.want || count != tc.count {
+			t.Errorf("replace %q with %q (%+v) in %q = %q, %d, want %q, %d", tc.query, tc.replacement, tc.options, tc.line, got, count, tc.want, tc.count)
+		}
+	}
+
+	for _, tc := range []struct {
+		query   string
+		options FindReplaceOptions
+	}{
+		{"", FindReplaceOptions{}},
+		{"(", FindReplaceOptions{Mode: findModeRegex}},
+		{"x", FindReplaceOptions{Mode: "fuzzy"}},
+	} {
+		if _, err := newReplaceMatcher(tc.query, "", tc.options); err == nil {
+			t.Errorf("newReplaceMatcher(%q, %+v) succeeded, want an error", tc.query, tc.options)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"*.md", "note.md", true},
+		{"*.md", "docs/deep/note.md", true},
+		{"draft-*", "docs/draft-1.md", true},
+		{"docs/*.md", "docs/note.md", true},
+		{"docs/*.md", "docs/deep/note.md", false},
+		{"docs/**/*.md", "docs/note.md", true},
+		{"docs/**/*.md", "docs/a/b/note.md", true},
+		{"/docs/**/", "docs/a/note.md", true},
+		{"**/archive/*", "a/b/archive/old.md", true},
+		{"**/archive/*", "archive.md", false},
+		{"docs/*.md", "other/note.md", false},
+		{"docs/**", "docs", true},
+	} {
+		if got := matchGlob(tc.pattern, tc.rel); got != tc.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tc.pattern, tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestFindReplaceSkipsTemplates(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	note := filepath.Join(root, "note.md")
+	template := filepath.Join(root, templatesDirName, "note.md")
+	writeTestFile(t, note, "old\n")
+	writeTestFile(t, template, "old {{title}}\n")
+
+	app := NewApp()
+	app.workspacePath = root
+	result, err := app.FindReplace("old", "new", FindReplaceOptions{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != note {
+		t.Fatalf("changed %+v, want only %s", result.Files, note)
+	}
+	if data, _ := os.ReadFile(template); string(data) != "old {{title}}\n" {
+		t.Errorf("the template was rewritten to %q", data)
+	}
+}
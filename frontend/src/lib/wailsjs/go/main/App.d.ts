@@ -4,50 +4,166 @@ import {main} from '../models';
 
 export function AddRecentFile(arg1:string):Promise<void>;
 
+export function AddWorkspace(arg1:string,arg2:string):Promise<main.Workspace>;
+
+export function BackupNow():Promise<main.BackupInfo>;
+
 export function ClearCurrentFile():Promise<main.CurrentFilesState>;
 
-export function CreateDir(arg1:string):Promise<void>;
+export function CloseTab(arg1:string):Promise<Array<main.Tab>>;
+
+export function CopyPath(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function CreateDir(arg1:string):Promise<string>;
 
-export function CreateFile(arg1:string):Promise<void>;
+export function CreateFile(arg1:string):Promise<string>;
+
+export function CreateFileFromTemplate(arg1:string,arg2:string,arg3:Record<string, string>):Promise<main.CreateFromTemplateResult>;
 
 export function DeleteFile(arg1:string):Promise<void>;
 
+export function ExportDOCX(arg1:string,arg2:main.DOCXExportOptions):Promise<string>;
+
+export function ExportEPUB(arg1:string,arg2:main.EPUBExportOptions):Promise<string>;
+
+export function ExportHTML(arg1:string,arg2:main.HTMLExportOptions):Promise<string>;
+
+export function ExportPDF(arg1:string,arg2:main.PDFExportOptions):Promise<string>;
+
+export function ExportWorkspaceZip(arg1:string):Promise<main.WorkspaceZipResult>;
+
+export function FindReplace(arg1:string,arg2:string,arg3:main.FindReplaceOptions):Promise<main.FindReplaceResult>;
+
+export function GenerateSite(arg1:main.SiteOptions):Promise<main.SiteResult>;
+
+export function GetActiveWorkspace():Promise<main.Workspace>;
+
+export function GetBackupSettings():Promise<main.BackupSettings>;
+
 export function GetConfig():Promise<main.Config>;
 
 export function GetContentHash(arg1:string):Promise<string>;
 
 export function GetCurrentFilesState():Promise<main.CurrentFilesState>;
 
+export function GetDailyNoteSettings():Promise<main.DailyNoteSettings>;
+
 export function GetFileContent(arg1:string):Promise<string>;
 
 export function GetFileContentPreview(arg1:string):Promise<string>;
 
-export function GetRecentFiles():Promise<Array<string>>;
+export function GetFileNameSettings():Promise<main.FileNameSettings>;
+
+export function GetFolderTemplate(arg1:string):Promise<string>;
+
+export function GetGitSettings():Promise<main.GitSettings>;
+
+export function GetOutline(arg1:string):Promise<Array<main.OutlineHeading>>;
+
+export function GetRecentFiles():Promise<Array<main.RecentFile>>;
 
 export function GetShowHiddenFiles():Promise<boolean>;
 
+export function GetViewState(arg1:string):Promise<main.ViewState>;
+
+export function GetWindowGeometry():Promise<main.WindowGeometry>;
+
+export function GitCheckoutFile(arg1:string,arg2:string):Promise<void>;
+
+export function GitDiff(arg1:string,arg2:string):Promise<string>;
+
+export function GitLog(arg1:string):Promise<Array<main.GitCommit>>;
+
 export function GoUp():Promise<main.CurrentFilesState>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportFile(arg1:string,arg2:string):Promise<string>;
+
+export function ImportNotion(arg1:string,arg2:string):Promise<main.ImportReport>;
+
+export function ImportObsidian(arg1:string,arg2:string):Promise<main.ImportReport>;
+
+export function ImportWorkspaceZip(arg1:string,arg2:string):Promise<main.WorkspaceZipResult>;
+
+export function IsGitRepository():Promise<boolean>;
+
+export function LintFile(arg1:string):Promise<Array<main.LintIssue>>;
+
+export function LintWorkspace():Promise<Array<main.LintIssue>>;
+
+export function ListBackups():Promise<Array<main.BackupInfo>>;
+
 export function ListFiles(arg1:string):Promise<Array<main.FileEntry>>;
 
+export function ListTabs():Promise<Array<main.Tab>>;
+
+export function ListTasks(arg1:main.TaskFilter):Promise<Array<main.Task>>;
+
+export function ListTemplates():Promise<Array<main.NoteTemplate>>;
+
+export function ListWorkspaces():Promise<Array<main.Workspace>>;
+
+export function NextDailyNote(arg1:string):Promise<main.DailyNote>;
+
+export function OpenDailyNote(arg1:string):Promise<main.CurrentFilesState>;
+
 export function OpenFile(arg1:string):Promise<main.CurrentFilesState>;
 
+export function OpenTab(arg1:string):Promise<Array<main.Tab>>;
+
 export function PickImageFile():Promise<string>;
 
-export function RenameFile(arg1:string,arg2:string):Promise<void>;
+export function PinRecentFile(arg1:string,arg2:boolean):Promise<void>;
+
+export function PreviousDailyNote(arg1:string):Promise<main.DailyNote>;
+
+export function RemoveRecentFile(arg1:string):Promise<void>;
+
+export function RemoveWorkspace(arg1:string):Promise<void>;
+
+export function RenameFile(arg1:string,arg2:string):Promise<string>;
 
 export function ResolveImagePath(arg1:string):Promise<string>;
 
+export function RestoreBackup(arg1:string):Promise<main.WorkspaceZipResult>;
+
 export function SaveFile(arg1:string,arg2:string):Promise<void>;
 
+export function SaveViewState(arg1:string,arg2:main.ViewState):Promise<void>;
+
+export function SaveWindowGeometry():Promise<void>;
+
+export function SetActiveTab(arg1:string):Promise<Array<main.Tab>>;
+
+export function SetBackupSettings(arg1:main.BackupSettings):Promise<void>;
+
+export function SetDailyNoteSettings(arg1:main.DailyNoteSettings):Promise<void>;
+
+export function SetFileNameSettings(arg1:main.FileNameSettings):Promise<void>;
+
+export function SetFolderTemplate(arg1:string,arg2:string):Promise<void>;
+
+export function SetGitSettings(arg1:main.GitSettings):Promise<void>;
+
+export function SetMaxRecentFiles(arg1:number):Promise<void>;
+
 export function SetShowHiddenFiles(arg1:boolean):Promise<void>;
 
 export function SetWindowTitle(arg1:string):Promise<void>;
 
+export function SwitchWorkspace(arg1:string):Promise<main.CurrentFilesState>;
+
+export function ToggleTask(arg1:string,arg2:number):Promise<main.Task>;
+
+export function UndoFindReplace(arg1:string):Promise<Array<string>>;
+
 export function UpdateConfig(arg1:string):Promise<void>;
 
 export function UpdateConfigField(arg1:string,arg2:string):Promise<void>;
 
+export function UpdateTOC(arg1:string,arg2:main.TOCOptions):Promise<main.TOCResult>;
+
+export function UpdateTabContent(arg1:string,arg2:string):Promise<main.Tab>;
+
 export function UpdateWindowTitleWithCurrentDir():Promise<void>;
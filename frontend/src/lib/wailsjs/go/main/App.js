@@ -6,10 +6,26 @@ export function AddRecentFile(arg1) {
   return window['go']['main']['App']['AddRecentFile'](arg1);
 }
 
+export function AddWorkspace(arg1, arg2) {
+  return window['go']['main']['App']['AddWorkspace'](arg1, arg2);
+}
+
+export function BackupNow() {
+  return window['go']['main']['App']['BackupNow']();
+}
+
 export function ClearCurrentFile() {
   return window['go']['main']['App']['ClearCurrentFile']();
 }
 
+export function CloseTab(arg1) {
+  return window['go']['main']['App']['CloseTab'](arg1);
+}
+
+export function CopyPath(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CopyPath'](arg1, arg2, arg3, arg4);
+}
+
 export function CreateDir(arg1) {
   return window['go']['main']['App']['CreateDir'](arg1);
 }
@@ -18,10 +34,50 @@ export function CreateFile(arg1) {
   return window['go']['main']['App']['CreateFile'](arg1);
 }
 
+export function CreateFileFromTemplate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateFileFromTemplate'](arg1, arg2, arg3);
+}
+
 export function DeleteFile(arg1) {
   return window['go']['main']['App']['DeleteFile'](arg1);
 }
 
+export function ExportDOCX(arg1, arg2) {
+  return window['go']['main']['App']['ExportDOCX'](arg1, arg2);
+}
+
+export function ExportEPUB(arg1, arg2) {
+  return window['go']['main']['App']['ExportEPUB'](arg1, arg2);
+}
+
+export function ExportHTML(arg1, arg2) {
+  return window['go']['main']['App']['ExportHTML'](arg1, arg2);
+}
+
+export function ExportPDF(arg1, arg2) {
+  return window['go']['main']['App']['ExportPDF'](arg1, arg2);
+}
+
+export function ExportWorkspaceZip(arg1) {
+  return window['go']['main']['App']['ExportWorkspaceZip'](arg1);
+}
+
+export function FindReplace(arg1, arg2, arg3) {
+  return window['go']['main']['App']['FindReplace'](arg1, arg2, arg3);
+}
+
+export function GenerateSite(arg1) {
+  return window['go']['main']['App']['GenerateSite'](arg1);
+}
+
+export function GetActiveWorkspace() {
+  return window['go']['main']['App']['GetActiveWorkspace']();
+}
+
+export function GetBackupSettings() {
+  return window['go']['main']['App']['GetBackupSettings']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
@@ -34,6 +90,10 @@ export function GetCurrentFilesState() {
   return window['go']['main']['App']['GetCurrentFilesState']();
 }
 
+export function GetDailyNoteSettings() {
+  return window['go']['main']['App']['GetDailyNoteSettings']();
+}
+
 export function GetFileContent(arg1) {
   return window['go']['main']['App']['GetFileContent'](arg1);
 }
@@ -42,6 +102,22 @@ export function GetFileContentPreview(arg1) {
   return window['go']['main']['App']['GetFileContentPreview'](arg1);
 }
 
+export function GetFileNameSettings() {
+  return window['go']['main']['App']['GetFileNameSettings']();
+}
+
+export function GetFolderTemplate(arg1) {
+  return window['go']['main']['App']['GetFolderTemplate'](arg1);
+}
+
+export function GetGitSettings() {
+  return window['go']['main']['App']['GetGitSettings']();
+}
+
+export function GetOutline(arg1) {
+  return window['go']['main']['App']['GetOutline'](arg1);
+}
+
 export function GetRecentFiles() {
   return window['go']['main']['App']['GetRecentFiles']();
 }
@@ -50,6 +126,26 @@ export function GetShowHiddenFiles() {
   return window['go']['main']['App']['GetShowHiddenFiles']();
 }
 
+export function GetViewState(arg1) {
+  return window['go']['main']['App']['GetViewState'](arg1);
+}
+
+export function GetWindowGeometry() {
+  return window['go']['main']['App']['GetWindowGeometry']();
+}
+
+export function GitCheckoutFile(arg1, arg2) {
+  return window['go']['main']['App']['GitCheckoutFile'](arg1, arg2);
+}
+
+export function GitDiff(arg1, arg2) {
+  return window['go']['main']['App']['GitDiff'](arg1, arg2);
+}
+
+export function GitLog(arg1) {
+  return window['go']['main']['App']['GitLog'](arg1);
+}
+
 export function GoUp() {
   return window['go']['main']['App']['GoUp']();
 }
@@ -58,18 +154,94 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportFile(arg1, arg2) {
+  return window['go']['main']['App']['ImportFile'](arg1, arg2);
+}
+
+export function ImportNotion(arg1, arg2) {
+  return window['go']['main']['App']['ImportNotion'](arg1, arg2);
+}
+
+export function ImportObsidian(arg1, arg2) {
+  return window['go']['main']['App']['ImportObsidian'](arg1, arg2);
+}
+
+export function ImportWorkspaceZip(arg1, arg2) {
+  return window['go']['main']['App']['ImportWorkspaceZip'](arg1, arg2);
+}
+
+export function IsGitRepository() {
+  return window['go']['main']['App']['IsGitRepository']();
+}
+
+export function LintFile(arg1) {
+  return window['go']['main']['App']['LintFile'](arg1);
+}
+
+export function LintWorkspace() {
+  return window['go']['main']['App']['LintWorkspace']();
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
 export function ListFiles(arg1) {
   return window['go']['main']['App']['ListFiles'](arg1);
 }
 
+export function ListTabs() {
+  return window['go']['main']['App']['ListTabs']();
+}
+
+export function ListTasks(arg1) {
+  return window['go']['main']['App']['ListTasks'](arg1);
+}
+
+export function ListTemplates() {
+  return window['go']['main']['App']['ListTemplates']();
+}
+
+export function ListWorkspaces() {
+  return window['go']['main']['App']['ListWorkspaces']();
+}
+
+export function NextDailyNote(arg1) {
+  return window['go']['main']['App']['NextDailyNote'](arg1);
+}
+
+export function OpenDailyNote(arg1) {
+  return window['go']['main']['App']['OpenDailyNote'](arg1);
+}
+
 export function OpenFile(arg1) {
   return window['go']['main']['App']['OpenFile'](arg1);
 }
 
+export function OpenTab(arg1) {
+  return window['go']['main']['App']['OpenTab'](arg1);
+}
+
 export function PickImageFile() {
   return window['go']['main']['App']['PickImageFile']();
 }
 
+export function PinRecentFile(arg1, arg2) {
+  return window['go']['main']['App']['PinRecentFile'](arg1, arg2);
+}
+
+export function PreviousDailyNote(arg1) {
+  return window['go']['main']['App']['PreviousDailyNote'](arg1);
+}
+
+export function RemoveRecentFile(arg1) {
+  return window['go']['main']['App']['RemoveRecentFile'](arg1);
+}
+
+export function RemoveWorkspace(arg1) {
+  return window['go']['main']['App']['RemoveWorkspace'](arg1);
+}
+
 export function RenameFile(arg1, arg2) {
   return window['go']['main']['App']['RenameFile'](arg1, arg2);
 }
@@ -78,10 +250,50 @@ export function ResolveImagePath(arg1) {
   return window['go']['main']['App']['ResolveImagePath'](arg1);
 }
 
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
 export function SaveFile(arg1, arg2) {
   return window['go']['main']['App']['SaveFile'](arg1, arg2);
 }
 
+export function SaveViewState(arg1, arg2) {
+  return window['go']['main']['App']['SaveViewState'](arg1, arg2);
+}
+
+export function SaveWindowGeometry() {
+  return window['go']['main']['App']['SaveWindowGeometry']();
+}
+
+export function SetActiveTab(arg1) {
+  return window['go']['main']['App']['SetActiveTab'](arg1);
+}
+
+export function SetBackupSettings(arg1) {
+  return window['go']['main']['App']['SetBackupSettings'](arg1);
+}
+
+export function SetDailyNoteSettings(arg1) {
+  return window['go']['main']['App']['SetDailyNoteSettings'](arg1);
+}
+
+export function SetFileNameSettings(arg1) {
+  return window['go']['main']['App']['SetFileNameSettings'](arg1);
+}
+
+export function SetFolderTemplate(arg1, arg2) {
+  return window['go']['main']['App']['SetFolderTemplate'](arg1, arg2);
+}
+
+export function SetGitSettings(arg1) {
+  return window['go']['main']['App']['SetGitSettings'](arg1);
+}
+
+export function SetMaxRecentFiles(arg1) {
+  return window['go']['main']['App']['SetMaxRecentFiles'](arg1);
+}
+
 export function SetShowHiddenFiles(arg1) {
   return window['go']['main']['App']['SetShowHiddenFiles'](arg1);
 }
@@ -90,6 +302,18 @@ export function SetWindowTitle(arg1) {
   return window['go']['main']['App']['SetWindowTitle'](arg1);
 }
 
+export function SwitchWorkspace(arg1) {
+  return window['go']['main']['App']['SwitchWorkspace'](arg1);
+}
+
+export function ToggleTask(arg1, arg2) {
+  return window['go']['main']['App']['ToggleTask'](arg1, arg2);
+}
+
+export function UndoFindReplace(arg1) {
+  return window['go']['main']['App']['UndoFindReplace'](arg1);
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['main']['App']['UpdateConfig'](arg1);
 }
@@ -98,6 +322,14 @@ export function UpdateConfigField(arg1, arg2) {
   return window['go']['main']['App']['UpdateConfigField'](arg1, arg2);
 }
 
+export function UpdateTOC(arg1, arg2) {
+  return window['go']['main']['App']['UpdateTOC'](arg1, arg2);
+}
+
+export function UpdateTabContent(arg1, arg2) {
+  return window['go']['main']['App']['UpdateTabContent'](arg1, arg2);
+}
+
 export function UpdateWindowTitleWithCurrentDir() {
   return window['go']['main']['App']['UpdateWindowTitleWithCurrentDir']();
 }
@@ -1,14 +1,207 @@
 export namespace main {
 	
+	export class BackupInfo {
+	    name: string;
+	    path: string;
+	    workspace: string;
+	    // Go type: time
+	    created: any;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.workspace = source["workspace"];
+	        this.created = this.convertValues(source["created"], null);
+	        this.size = source["size"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupSettings {
+	    enabled: boolean;
+	    directory: string;
+	    intervalMinutes: number;
+	    keepDaily: number;
+	    keepWeekly: number;
+	    keepMonthly: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.directory = source["directory"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	        this.keepDaily = source["keepDaily"];
+	        this.keepWeekly = source["keepWeekly"];
+	        this.keepMonthly = source["keepMonthly"];
+	    }
+	}
+	export class FileNameSettings {
+	    slugify: boolean;
+	    addExtension: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileNameSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.slugify = source["slugify"];
+	        this.addExtension = source["addExtension"];
+	    }
+	}
+	export class DailyNoteSettings {
+	    pattern: string;
+	    template: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyNoteSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.template = source["template"];
+	    }
+	}
+	export class GitSettings {
+	    autoCommit: boolean;
+	    commitMessage: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.autoCommit = source["autoCommit"];
+	        this.commitMessage = source["commitMessage"];
+	    }
+	}
+	export class Workspace {
+	    name: string;
+	    path: string;
+	    lastOpenedFile: string;
+	    lastOpenedDirectory: string;
+	    recentFiles: RecentFile[];
+	    openTabs: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Workspace(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.lastOpenedFile = source["lastOpenedFile"];
+	        this.lastOpenedDirectory = source["lastOpenedDirectory"];
+	        this.recentFiles = this.convertValues(source["recentFiles"], RecentFile);
+	        this.openTabs = source["openTabs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecentFile {
+	    path: string;
+	    // Go type: time
+	    lastOpened: any;
+	    openCount: number;
+	    pinned: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecentFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.lastOpened = this.convertValues(source["lastOpened"], null);
+	        this.openCount = source["openCount"];
+	        this.pinned = source["pinned"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Config {
 	    lastOpenedFile: string;
 	    lastOpenedDirectory: string;
-	    recentFiles: string[];
+	    recentFiles: RecentFile[];
+	    maxRecentFiles: number;
+	    openTabs: string[];
 	    windowWidth: number;
 	    windowHeight: number;
+	    windowX: number;
+	    windowY: number;
+	    windowPositionSaved: boolean;
+	    windowMaximized: boolean;
 	    theme: string;
 	    showHiddenFiles: boolean;
 	    customSettings: Record<string, string>;
+	    workspaces: Workspace[];
+	    activeWorkspace: string;
+	    defaultSession: Workspace;
+	    backup: BackupSettings;
+	    git: GitSettings;
+	    dailyNotes: DailyNoteSettings;
+	    fileNames: FileNameSettings;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -18,12 +211,79 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.lastOpenedFile = source["lastOpenedFile"];
 	        this.lastOpenedDirectory = source["lastOpenedDirectory"];
-	        this.recentFiles = source["recentFiles"];
+	        this.recentFiles = this.convertValues(source["recentFiles"], RecentFile);
+	        this.maxRecentFiles = source["maxRecentFiles"];
+	        this.openTabs = source["openTabs"];
 	        this.windowWidth = source["windowWidth"];
 	        this.windowHeight = source["windowHeight"];
+	        this.windowX = source["windowX"];
+	        this.windowY = source["windowY"];
+	        this.windowPositionSaved = source["windowPositionSaved"];
+	        this.windowMaximized = source["windowMaximized"];
 	        this.theme = source["theme"];
 	        this.showHiddenFiles = source["showHiddenFiles"];
 	        this.customSettings = source["customSettings"];
+	        this.workspaces = this.convertValues(source["workspaces"], Workspace);
+	        this.activeWorkspace = source["activeWorkspace"];
+	        this.defaultSession = this.convertValues(source["defaultSession"], Workspace);
+	        this.backup = this.convertValues(source["backup"], BackupSettings);
+	        this.git = this.convertValues(source["git"], GitSettings);
+	        this.dailyNotes = this.convertValues(source["dailyNotes"], DailyNoteSettings);
+	        this.fileNames = this.convertValues(source["fileNames"], FileNameSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CreateFromTemplateResult {
+	    path: string;
+	    template: string;
+	    cursorOffset: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreateFromTemplateResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.template = source["template"];
+	        this.cursorOffset = source["cursorOffset"];
+	    }
+	}
+	export class Tab {
+	    path: string;
+	    name: string;
+	    dirty: boolean;
+	    contentHash: string;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tab(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.dirty = source["dirty"];
+	        this.contentHash = source["contentHash"];
+	        this.active = source["active"];
 	    }
 	}
 	export class FileEntry {
@@ -33,6 +293,7 @@ export namespace main {
 	    size: number;
 	    // Go type: time
 	    modTime: any;
+	    gitStatus?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileEntry(source);
@@ -45,6 +306,7 @@ export namespace main {
 	        this.isDirectory = source["isDirectory"];
 	        this.size = source["size"];
 	        this.modTime = this.convertValues(source["modTime"], null);
+	        this.gitStatus = source["gitStatus"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -70,6 +332,7 @@ export namespace main {
 	    currentFile?: FileEntry;
 	    fileInfo?: FileEntry;
 	    contentHash?: string;
+	    tabs?: Tab[];
 	
 	    static createFrom(source: any = {}) {
 	        return new CurrentFilesState(source);
@@ -81,6 +344,360 @@ export namespace main {
 	        this.currentFile = this.convertValues(source["currentFile"], FileEntry);
 	        this.fileInfo = this.convertValues(source["fileInfo"], FileEntry);
 	        this.contentHash = source["contentHash"];
+	        this.tabs = this.convertValues(source["tabs"], Tab);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DOCXExportOptions {
+	    outputPath: string;
+	    title: string;
+	    referenceDocx: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DOCXExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outputPath = source["outputPath"];
+	        this.title = source["title"];
+	        this.referenceDocx = source["referenceDocx"];
+	    }
+	}
+	export class DailyNote {
+	    date: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyNote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.path = source["path"];
+	    }
+	}
+	
+	export class EPUBExportOptions {
+	    outputPath: string;
+	    title: string;
+	    author: string;
+	    language: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EPUBExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outputPath = source["outputPath"];
+	        this.title = source["title"];
+	        this.author = source["author"];
+	        this.language = source["language"];
+	    }
+	}
+	
+	
+	export class FindReplaceChange {
+	    line: number;
+	    before: string;
+	    after: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FindReplaceChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class FindReplaceFile {
+	    path: string;
+	    matches: number;
+	    changes: FindReplaceChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FindReplaceFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.matches = source["matches"];
+	        this.changes = this.convertValues(source["changes"], FindReplaceChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FindReplaceOptions {
+	    mode: string;
+	    caseSensitive: boolean;
+	    folder: string;
+	    glob: string;
+	    apply: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FindReplaceOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.caseSensitive = source["caseSensitive"];
+	        this.folder = source["folder"];
+	        this.glob = source["glob"];
+	        this.apply = source["apply"];
+	    }
+	}
+	export class FindReplaceResult {
+	    files: FindReplaceFile[];
+	    matches: number;
+	    applied: boolean;
+	    snapshotId: string;
+	    skipped: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FindReplaceResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], FindReplaceFile);
+	        this.matches = source["matches"];
+	        this.applied = source["applied"];
+	        this.snapshotId = source["snapshotId"];
+	        this.skipped = source["skipped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GitCommit {
+	    hash: string;
+	    shortHash: string;
+	    author: string;
+	    email: string;
+	    // Go type: time
+	    date: any;
+	    subject: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitCommit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.shortHash = source["shortHash"];
+	        this.author = source["author"];
+	        this.email = source["email"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.subject = source["subject"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class HTMLExportOptions {
+	    outputPath: string;
+	    theme: string;
+	    codeStyle: string;
+	    copyImages: boolean;
+	    title: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HTMLExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outputPath = source["outputPath"];
+	        this.theme = source["theme"];
+	        this.codeStyle = source["codeStyle"];
+	        this.copyImages = source["copyImages"];
+	        this.title = source["title"];
+	    }
+	}
+	export class ImportProblem {
+	    path: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportProblem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ImportReport {
+	    source: string;
+	    targetDir: string;
+	    notes: number;
+	    tables: number;
+	    attachments: number;
+	    problems: ImportProblem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.targetDir = source["targetDir"];
+	        this.notes = source["notes"];
+	        this.tables = source["tables"];
+	        this.attachments = source["attachments"];
+	        this.problems = this.convertValues(source["problems"], ImportProblem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LintIssue {
+	    path: string;
+	    rule: string;
+	    severity: string;
+	    line: number;
+	    column: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LintIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.rule = source["rule"];
+	        this.severity = source["severity"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.message = source["message"];
+	    }
+	}
+	export class NoteTemplate {
+	    name: string;
+	    path: string;
+	    prompts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NoteTemplate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.prompts = source["prompts"];
+	    }
+	}
+	export class OutlineHeading {
+	    level: number;
+	    text: string;
+	    slug: string;
+	    line: number;
+	    endLine: number;
+	    children: OutlineHeading[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OutlineHeading(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.level = source["level"];
+	        this.text = source["text"];
+	        this.slug = source["slug"];
+	        this.line = source["line"];
+	        this.endLine = source["endLine"];
+	        this.children = this.convertValues(source["children"], OutlineHeading);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -101,6 +718,224 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class PDFExportOptions {
+	    outputPath: string;
+	    pageSize: string;
+	    title: string;
+	    noToc: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PDFExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outputPath = source["outputPath"];
+	        this.pageSize = source["pageSize"];
+	        this.title = source["title"];
+	        this.noToc = source["noToc"];
+	    }
+	}
+	
+	export class SiteOptions {
+	    sourceDir: string;
+	    outputDir: string;
+	    title: string;
+	    description: string;
+	    baseUrl: string;
+	    theme: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SiteOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sourceDir = source["sourceDir"];
+	        this.outputDir = source["outputDir"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.baseUrl = source["baseUrl"];
+	        this.theme = source["theme"];
+	    }
+	}
+	export class SiteResult {
+	    outputDir: string;
+	    pages: number;
+	    assets: number;
+	    skipped: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SiteResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outputDir = source["outputDir"];
+	        this.pages = source["pages"];
+	        this.assets = source["assets"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class TOCOptions {
+	    minLevel: number;
+	    maxLevel: number;
+	    numbered: boolean;
+	    anchorStyle: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TOCOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minLevel = source["minLevel"];
+	        this.maxLevel = source["maxLevel"];
+	        this.numbered = source["numbered"];
+	        this.anchorStyle = source["anchorStyle"];
+	    }
+	}
+	export class TOCResult {
+	    path: string;
+	    toc: string;
+	    changed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TOCResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.toc = source["toc"];
+	        this.changed = source["changed"];
+	    }
+	}
+	
+	export class Task {
+	    path: string;
+	    line: number;
+	    text: string;
+	    done: boolean;
+	    heading: string;
+	    due: string;
+	    assignees: string[];
+	    priority: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Task(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.line = source["line"];
+	        this.text = source["text"];
+	        this.done = source["done"];
+	        this.heading = source["heading"];
+	        this.due = source["due"];
+	        this.assignees = source["assignees"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class TaskFilter {
+	    folder: string;
+	    status: string;
+	    assignee: string;
+	    priority: string;
+	    dueBefore: string;
+	    query: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder = source["folder"];
+	        this.status = source["status"];
+	        this.assignee = source["assignee"];
+	        this.priority = source["priority"];
+	        this.dueBefore = source["dueBefore"];
+	        this.query = source["query"];
+	    }
+	}
+	export class ViewState {
+	    cursorOffset: number;
+	    scrollTop: number;
+	    foldedHeadings: string[];
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ViewState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cursorOffset = source["cursorOffset"];
+	        this.scrollTop = source["scrollTop"];
+	        this.foldedHeadings = source["foldedHeadings"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WindowGeometry {
+	    width: number;
+	    height: number;
+	    x: number;
+	    y: number;
+	    maximized: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowGeometry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.maximized = source["maximized"];
+	    }
+	}
+	
+	export class WorkspaceZipResult {
+	    path: string;
+	    files: number;
+	    bytes: number;
+	    skipped: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceZipResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.files = source["files"];
+	        this.bytes = source["bytes"];
+	        this.skipped = source["skipped"];
+	    }
+	}
 
 }
 
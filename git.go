@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Git status values reported in FileEntry.GitStatus
+const (
+	gitStatusModified  = "modified"  // changed in the working tree
+	gitStatusStaged    = "staged"    // changes staged for the next commit
+	gitStatusUntracked = "untracked" // not tracked by git
+)
+
+// defaultCommitMessage is the auto-commit message template used when none is configured
+const defaultCommitMessage = "Update {{.File}}"
+
+// GitSettings controls the git integration for git-backed workspaces
+type GitSettings struct {
+	AutoCommit bool `json:"autoCommit"` // commit each file after it is saved
+	// CommitMessage is a text/template with .File, .Name, .Workspace, .Date
+	// and .Time. When one commit covers several files, as after a find and
+	// replace, .File and .Name read "N files".
+	CommitMessage string `json:"commitMessage"`
+}
+
+// GitCommit is an entry in a file's history
+type GitCommit struct {
+	Hash      string    `json:"hash"`
+	ShortHash string    `json:"shortHash"`
+	Author    string    `json:"author"`
+	Email     string    `json:"email"`
+	Date      time.Time `json:"date"`
+	Subject   string    `json:"subject"`
+}
+
+// commitMessageData is passed to the commit message template
+type commitMessageData struct {
+	File      string // path relative to the repository
+	Name      string // file name
+	Workspace string
+	Date      string
+	Time      string
+}
+
+// gitRoot returns the workspace root when it is a git repository with the
+// git command available, or "" otherwise
+func (a *App) gitRoot() string {
+	root := a.workspaceRoot()
+	if root == "" {
+		return ""
+	}
+	// A worktree or submodule has a .git file rather than a directory
+	if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+		return ""
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return ""
+	}
+	return root
+}
+
+// runGit runs git in root and returns its standard output
+func runGit(root string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	// Keep git from opening an editor or pager, or asking for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true", "GIT_PAGER=cat")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], message)
+	}
+	return stdout.String(), nil
+}
+
+// gitRelativePath returns path relative to the repository root, rejecting paths outside it
+func gitRelativePath(root string, path string) (string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not inside the repository %s", path, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// validateRevision rejects revisions git would read as options
+func validateRevision(rev string) error {
+	if strings.HasPrefix(rev, "-") {
+		return fmt.Errorf("invalid revision %q", rev)
+	}
+	return nil
+}
+
+// gitStatuses returns the status of every changed file in the repository by
+// absolute path. Folders containing changes are reported too, with the status
+// shared by everything changed inside them, or modified when it is mixed.
+func gitStatuses(root string) (map[string]string, error) {
+	output, err := runGit(root, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]string{}
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, rel := entry[0], entry[1], entry[3:]
+		// Renames and copies are followed by the original path
+		if x == 'R' || x == 'C' {
+			i++
+		}
+
+		var status string
+		switch {
+		case x == '?' && y == '?':
+			status = gitStatusUntracked
+		case y != ' ':
+			status = gitStatusModified
+		default:
+			status = gitStatusStaged
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		statuses[path] = status
+
+		// Roll the status up into the parent folders
+		for dir := filepath.Dir(path); dir != root && isSameOrChildPath(dir, root); dir = filepath.Dir(dir) {
+			if existing, ok := statuses[dir]; !ok {
+				statuses[dir] = status
+			} else if existing != status {
+				statuses[dir] = gitStatusModified
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+// IsGitRepository reports whether the workspace root is a git repository
+func (a *App) IsGitRepository() bool {
+	return a.gitRoot() != ""
+}
+
+// GetGitSettings returns the git integration settings
+func (a *App) GetGitSettings() (GitSettings, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return GitSettings{}, err
+	}
+	return config.Git, nil
+}
+
+// SetGitSettings saves the git integration settings
+func (a *App) SetGitSettings(settings GitSettings) error {
+	if settings.CommitMessage != "" {
+		if _, err := template.New("commit").Parse(settings.CommitMessage); err != nil {
+			return fmt.Errorf("invalid commit message template: %w", err)
+		}
+	}
+
+	return updateConfig(func(config *Config) error {
+		config.Git = settings
+		return nil
+	})
+}
+
+// autoCommit commits paths in a single commit after they are saved when
+// auto-commit is enabled. Failures are logged rather than failing the save.
+func (a *App) autoCommit(paths ...string) {
+	if len(paths) == 0 {
+		return
+	}
+	root := a.gitRoot()
+	if root == "" {
+		return
+	}
+
+	config, err := LoadConfig()
+	if err != nil || !config.Git.AutoCommit {
+		return
+	}
+
+	if err := commitFiles(root, paths, config.Git.CommitMessage, a.workspaceName); err != nil {
+		fmt.Printf("Warning: Could not auto-commit %s: %v\n", strings.Join(paths, ", "), err)
+	}
+}
+
+// commitFiles stages and commits files together with a message rendered from
+// messageTemplate. Files the save didn't change are left out, and nothing is
+// committed when none changed.
+func commitFiles(root string, paths []string, messageTemplate string, workspace string) error {
+	var rels []string
+	for _, path := range paths {
+		rel, err := gitRelativePath(root, path)
+		if err != nil {
+			return err
+		}
+		rels = append(rels, rel)
+	}
+
+	status, err := runGit(root, append([]string{"status", "--porcelain", "-z", "--"}, rels...)...)
+	if err != nil {
+		return err
+	}
+	changed := map[string]bool{}
+	for _, entry := range strings.Split(status, "\x00") {
+		if len(entry) >= 4 {
+			changed[entry[3:]] = true
+		}
+	}
+	var commit []string
+	path := ""
+	for i, rel := range rels {
+		if changed[rel] {
+			commit = append(commit, rel)
+			path = paths[i]
+		}
+	}
+	if len(commit) == 0 {
+		return nil
+	}
+
+	file, name := commit[0], filepath.Base(path)
+	if len(commit) > 1 {
+		file = fmt.Sprintf("%d files", len(commit))
+		name = file
+	}
+
+	if messageTemplate == "" {
+		messageTemplate = defaultCommitMessage
+	}
+	tmpl, err := template.New("commit").Parse(messageTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid commit message template: %w", err)
+	}
+	now := time.Now()
+	var message bytes.Buffer
+	err = tmpl.Execute(&message, commitMessageData{
+		File:      file,
+		Name:      name,
+		Workspace: workspace,
+		Date:      now.Format("2006-01-02"),
+		Time:      now.Format("15:04"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render commit message: %w", err)
+	}
+
+	if _, err := runGit(root, append([]string{"add", "--"}, commit...)...); err != nil {
+		return err
+	}
+	// Only these files are committed, whatever else is staged
+	_, err = runGit(root, append([]string{"commit", "--quiet", "-m", message.String(), "--only", "--"}, commit...)...)
+	return err
+}
+
+// GitLog returns the commits that touched path, newest first, following
+// renames. An empty path returns the history of the whole repository.
+func (a *App) GitLog(path string) ([]GitCommit, error) {
+	root := a.gitRoot()
+	if root == "" {
+		return nil, fmt.Errorf("the workspace is not a git repository")
+	}
+
+	args := []string{"log", "--max-count=500", "--format=%H%x1f%h%x1f%an%x1f%ae%x1f%aI%x1f%s%x1e"}
+	if path != "" {
+		rel, err := gitRelativePath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--follow", "--", rel)
+	}
+
+	output, err := runGit(root, args...)
+	if err != nil {
+		// A repository without commits has no history yet
+		if _, headErr := runGit(root, "rev-parse", "--verify", "HEAD"); headErr != nil {
+			return []GitCommit{}, nil
+		}
+		return nil, err
+	}
+
+	commits := []GitCommit{}
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) != 6 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[4])
+		commits = append(commits, GitCommit{
+			Hash:      fields[0],
+			ShortHash: fields[1],
+			Author:    fields[2],
+			Email:     fields[3],
+			Date:      date,
+			Subject:   fields[5],
+		})
+	}
+	return commits, nil
+}
+
+// GitDiff returns a unified diff of path between rev and the working tree.
+// An empty rev compares against the last commit.
+func (a *App) GitDiff(path string, rev string) (string, error) {
+	root := a.gitRoot()
+	if root == "" {
+		return "", fmt.Errorf("the workspace is not a git repository")
+	}
+	if err := validateRevision(rev); err != nil {
+		return "", err
+	}
+	rel, err := gitRelativePath(root, path)
+	if err != nil {
+		return "", err
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	return runGit(root, "diff", "--no-color", "--no-ext-diff", rev, "--", rel)
+}
+
+// GitCheckoutFile replaces path with its content at rev, the last commit when
+// rev is empty, and reloads it if it is open. A note with unsaved edits in a
+// tab is refused, since the checkout would leave the tab showing stale edits.
+func (a *App) GitCheckoutFile(path string, rev string) error {
+	if a.hasUnsavedEdits(path) {
+		return fmt.Errorf("%s has unsaved changes, save or discard them before checking it out", path)
+	}
+
+	root := a.gitRoot()
+	if root == "" {
+		return fmt.Errorf("the workspace is not a git repository")
+	}
+	if err := validateRevision(rev); err != nil {
+		return err
+	}
+	rel, err := gitRelativePath(root, path)
+	if err != nil {
+		return err
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	if _, err := runGit(root, "checkout", rev, "--", rel); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	if a.currentFile == path {
+		a.currentFileContent = string(content)
+	}
+	a.markTabSaved(path, string(content))
+	a.notifyNotesChanged(path)
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a throwaway git repository with one committed note and
+// returns an app whose workspace it is. Config goes to a temporary folder.
+func newTestRepo(t *testing.T, autoCommit bool) (*App, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	// Keep the user's git config, such as commit signing, out of the tests
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "note.md"), "first\n")
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "note.md"},
+		{"commit", "--quiet", "-m", "Initial commit"},
+	} {
+		if _, err := runGit(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := updateConfig(func(config *Config) error {
+		config.Git = GitSettings{AutoCommit: autoCommit, CommitMessage: defaultCommitMessage}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	app.workspacePath = root
+	app.currentDir = root
+	return app, root
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commitSubjects returns the subjects of the repository's commits, newest first
+func commitSubjects(t *testing.T, root string) []string {
+	t.Helper()
+	output, err := runGit(root, "log", "--format=%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(output), "\n")
+}
+
+func TestGitStatuses(t *testing.T) {
+	app, root := newTestRepo(t, false)
+
+	writeTestFile(t, filepath.Join(root, "note.md"), "changed\n")
+	writeTestFile(t, filepath.Join(root, "new", "untracked.md"), "new\n")
+	writeTestFile(t, filepath.Join(root, "staged", "added.md"), "staged\n")
+	if _, err := runGit(root, "add", "staged/added.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := gitStatuses(app.gitRoot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		filepath.Join(root, "note.md"):             gitStatusModified,
+		filepath.Join(root, "new", "untracked.md"): gitStatusUntracked,
+		filepath.Join(root, "new"):                 gitStatusUntracked,
+		filepath.Join(root, "staged", "added.md"):  gitStatusStaged,
+		filepath.Join(root, "staged"):              gitStatusStaged,
+	}
+	for path, status := range want {
+		if statuses[path] != status {
+			t.Errorf("status of %s = %q, want %q", path, statuses[path], status)
+		}
+	}
+}
+
+func TestSaveFileAutoCommits(t *testing.T) {
+	app, root := newTestRepo(t, true)
+	note := filepath.Join(root, "note.md")
+
+	if err := app.SaveFile(note, "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	subjects := commitSubjects(t, root)
+	if len(subjects) != 2 || subjects[0] != "Update note.md" {
+		t.Fatalf("commits = %q, want the save committed as \"Update note.md\"", subjects)
+	}
+
+	// Saving the same content again has nothing to commit
+	if err := app.SaveFile(note, "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	if subjects := commitSubjects(t, root); len(subjects) != 2 {
+		t.Fatalf("commits = %q, want no commit for an unchanged save", subjects)
+	}
+}
+
+func TestSaveFileWithoutAutoCommit(t *testing.T) {
+	app, root := newTestRepo(t, false)
+
+	if err := app.SaveFile(filepath.Join(root, "note.md"), "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	if subjects := commitSubjects(t, root); len(subjects) != 1 {
+		t.Fatalf("commits = %q, want none added with auto-commit off", subjects)
+	}
+}
+
+func TestGitLogAndDiff(t *testing.T) {
+	app, root := newTestRepo(t, true)
+	note := filepath.Join(root, "note.md")
+
+	if err := app.SaveFile(note, "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	commits, err := app.GitLog(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Update note.md" || commits[1].Subject != "Initial commit" {
+		t.Fatalf("history = %+v, want the save then the initial commit", commits)
+	}
+
+	writeTestFile(t, note, "third\n")
+	diff, err := app.GitDiff(note, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-second") || !strings.Contains(diff, "+third") {
+		t.Fatalf("diff against HEAD = %q", diff)
+	}
+
+	if err := app.GitCheckoutFile(note, commits[1].Hash); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(note); string(data) != "first\n" {
+		t.Fatalf("after checkout the note reads %q, want the first version", data)
+	}
+
+	// A tab with unsaved edits keeps them instead of being checked out under
+	if _, err := app.OpenTab(note); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTabContent(note, "unsaved\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.GitCheckoutFile(note, commits[0].Hash); err == nil {
+		t.Fatal("GitCheckoutFile replaced a note with unsaved edits")
+	}
+	if !app.hasUnsavedEdits(note) {
+		t.Error("the tab lost its unsaved edits")
+	}
+}
+
+func TestFindReplaceCommitsOnce(t *testing.T) {
+	app, root := newTestRepo(t, true)
+	writeTestFile(t, filepath.Join(root, "a.md"), "old text\n")
+	writeTestFile(t, filepath.Join(root, "b.md"), "more old text\n")
+	if _, err := runGit(root, "add", "a.md", "b.md"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(root, "commit", "--quiet", "-m", "Add notes"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := app.FindReplace("old", "new", FindReplaceOptions{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Applied || len(result.Files) != 2 {
+		t.Fatalf("result = %+v, want two notes changed", result)
+	}
+	subjects := commitSubjects(t, root)
+	if len(subjects) != 3 || subjects[0] != "Update 2 files" {
+		t.Fatalf("commits = %q, want one commit for the whole replacement", subjects)
+	}
+
+	if _, err := app.UndoFindReplace(result.SnapshotID); err != nil {
+		t.Fatal(err)
+	}
+	if subjects := commitSubjects(t, root); len(subjects) != 4 {
+		t.Fatalf("commits = %q, want one commit for the undo", subjects)
+	}
+}
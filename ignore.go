@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the per-workspace file listing paths that exports,
+// archives and workspace-wide tools skip
+const ignoreFileName = ".markdownsignore"
+
+// ignoreRule is one line of an ignore file
+type ignoreRule struct {
+	pattern  string
+	negate   bool // "!pattern" re-includes a path
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // "/pattern" or "a/b" only matches from the root
+}
+
+// ignoreRules decides which workspace paths are skipped. Hidden files and
+// folders are always skipped; the ignore file follows a subset of .gitignore
+// syntax: globs, "!" negation, a trailing "/" for directories and a leading
+// "/" to anchor at the root.
+type ignoreRules struct {
+	rules []ignoreRule
+}
+
+// loadIgnoreRules reads the ignore file at the workspace root, if there is one
+func loadIgnoreRules(root string) *ignoreRules {
+	rules := &ignoreRules{}
+
+	file, err := os.Open(filepath.Join(root, ignoreFileName))
+	if err != nil {
+		return rules
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.HasPrefix(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		} else if strings.Contains(line, "/") {
+			rule.anchored = true
+		}
+		rule.pattern = line
+		rules.rules = append(rules.rules, rule)
+	}
+
+	return rules
+}
+
+// matches reports whether relPath, relative to the workspace root, is ignored.
+// Callers walking a tree skip ignored directories entirely.
+func (r *ignoreRules) matches(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." || relPath == "" {
+		return false
+	}
+
+	name := path.Base(relPath)
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	ignored := false
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		var matched bool
+		if rule.anchored {
+			matched, _ = path.Match(rule.pattern, relPath)
+		} else {
+			matched, _ = path.Match(rule.pattern, name)
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// excludes reports whether relPath or any folder above it is ignored, for
+// checking a single path rather than walking down to it
+func (r *ignoreRules) excludes(relPath string, isDir bool) bool {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := range parts {
+		last := i == len(parts)-1
+		if r.matches(strings.Join(parts[:i+1], "/"), isDir || !last) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkWorkspace calls fn, in lexical order, for every file and directory under
+// root that the ignore rules keep. Directories listed in skipDirs are skipped
+// along with everything inside them. Folders and files that can't be read are
+// skipped with a warning on standard error, so one bad folder doesn't stop the
+// whole walk or corrupt output such as lint -json.
+func walkWorkspace(root string, skipDirs []string, fn func(path string, rel string, d fs.DirEntry) error) error {
+	rules := loadIgnoreRules(root)
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: Skipping %s: %v\n", p, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+
+		for _, skip := range skipDirs {
+			if d.IsDir() && filepath.Clean(p) == filepath.Clean(skip) {
+				return filepath.SkipDir
+			}
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rules.matches(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return fn(p, rel, d)
+	})
+}
+
+// walkNotes is walkWorkspace for operations on the notes themselves. It also
+// skips the templates folder, whose placeholders aren't notes yet.
+func walkNotes(root string, skipDirs []string, fn func(path string, rel string, d fs.DirEntry) error) error {
+	return walkWorkspace(root, append(skipDirs, noteSkipDirs(root)...), fn)
+}
+
+// noteSkipDirs returns the folders under a workspace root that hold no notes
+func noteSkipDirs(root string) []string {
+	if root == "" {
+		return nil
+	}
+	return []string{filepath.Join(root, templatesDirName)}
+}
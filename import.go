@@ -0,0 +1,838 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// attachmentsDirName is the folder, next to imported notes, that holds their images
+const attachmentsDirName = "attachments"
+
+// ImportFile converts a Word document (.docx) or web page (.html) into a new
+// markdown note in targetDir, the current directory when empty. The note is
+// named after the file, cleaned up as in CreateFile, and starts from the
+// folder's default template when it has one: the imported text replaces
+// {{content}} in the template, or follows it. Images are extracted to the
+// attachments folder once the note has been created, so a failed import
+// leaves nothing behind. It returns the new note's path.
+func (a *App) ImportFile(path string, targetDir string) (string, error) {
+	if targetDir == "" {
+		targetDir = a.currentDir
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("path %s is a directory, not a file", path)
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	rel, err := safeRelativeName(stem+".md", fileNameSettings(), true, ".md")
+	if err != nil {
+		return "", err
+	}
+	targetDir = filepath.Join(targetDir, filepath.Dir(rel))
+	name := filepath.Base(rel)
+	if _, err := os.Stat(filepath.Join(targetDir, name)); err == nil {
+		return "", fmt.Errorf("file %s already exists", name)
+	}
+
+	attachments := newAttachmentWriter(targetDir)
+
+	var content string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".docx":
+		content, err = convertDOCX(path, attachments)
+	case ".html", ".htm":
+		content, err = convertHTML(path, attachments)
+	default:
+		return "", fmt.Errorf("unsupported file type %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	content, err = a.applyFolderTemplate(targetDir, name, content)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", targetDir, err)
+	}
+	notePath, err := createFileWithContent(targetDir, name, content)
+	if err != nil {
+		return "", err
+	}
+	if err := attachments.flush(); err != nil {
+		os.Remove(notePath)
+		return "", err
+	}
+	return notePath, nil
+}
+
+// applyFolderTemplate wraps imported content in the default template of dir,
+// if it has one. The content replaces {{content}}, or is added after the
+// rendered template when it has no such variable.
+func (a *App) applyFolderTemplate(dir string, name string, content string) (string, error) {
+	template, err := a.GetFolderTemplate(dir)
+	if err != nil || template == "" {
+		return content, err
+	}
+	source, err := readNoteTemplate(a.workspaceRoot(), template)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	rendered, _ := renderNoteTemplate(source, noteTemplateContext{
+		Date:  now,
+		Now:   now,
+		Title: strings.TrimSuffix(name, filepath.Ext(name)),
+		Vars:  map[string]string{"content": content},
+	})
+	for _, parts := range noteVariablePattern.FindAllStringSubmatch(source, -1) {
+		if parts[1] == "content" && parts[2] == "" {
+			return rendered, nil
+		}
+	}
+	if rendered != "" && !strings.HasSuffix(rendered, "\n") {
+		rendered += "\n"
+	}
+	return rendered + content, nil
+}
+
+// attachmentWriter collects imported images under names that don't collide
+// with files already in the attachments folder, and writes them there with
+// flush once the note referencing them exists
+type attachmentWriter struct {
+	dir       string
+	usedNames map[string]bool
+	pending   []pendingAttachment
+}
+
+// pendingAttachment is an image waiting to be written by flush
+type pendingAttachment struct {
+	name string
+	data []byte
+}
+
+// newAttachmentWriter returns a writer for notes created in noteDir
+func newAttachmentWriter(noteDir string) *attachmentWriter {
+	w := &attachmentWriter{
+		dir:       filepath.Join(noteDir, attachmentsDirName),
+		usedNames: map[string]bool{},
+	}
+	if entries, err := os.ReadDir(w.dir); err == nil {
+		for _, entry := range entries {
+			w.usedNames[strings.ToLower(entry.Name())] = true
+		}
+	}
+	return w
+}
+
+// save queues data to be written as name and returns the markdown reference
+// to it, relative to the note
+func (w *attachmentWriter) save(name string, data []byte) (string, error) {
+	name = uniqueFileName(sanitizePackageName(name), w.usedNames)
+	w.pending = append(w.pending, pendingAttachment{name: name, data: data})
+
+	return attachmentsDirName + "/" + url.PathEscape(name), nil
+}
+
+// flush writes the queued attachments. If one fails, those already written
+// are removed again.
+func (w *attachmentWriter) flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", w.dir, err)
+	}
+
+	for i, attachment := range w.pending {
+		if err := os.WriteFile(filepath.Join(w.dir, attachment.name), attachment.data, 0644); err != nil {
+			for _, written := range w.pending[:i] {
+				os.Remove(filepath.Join(w.dir, written.name))
+			}
+			return fmt.Errorf("failed to write attachment %s: %w", attachment.name, err)
+		}
+	}
+
+	w.pending = nil
+	return nil
+}
+
+// convertHTML converts a saved web page to markdown, copying local and
+// inline (data: URI) images to the attachments folder
+func convertHTML(path string, attachments *attachmentWriter) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	baseDir := filepath.Dir(path)
+	doc.Find("img[src]").Each(func(i int, img *goquery.Selection) {
+		src, _ := img.Attr("src")
+		ref, err := importHTMLImage(baseDir, src, attachments)
+		if err != nil {
+			fmt.Printf("Warning: Could not import image %s: %v\n", src, err)
+			return
+		}
+		if ref != "" {
+			img.SetAttr("src", ref)
+		}
+	})
+
+	converter := md.NewConverter("", true, &md.Options{
+		CodeBlockStyle: "fenced",
+		EmDelimiter:    "*",
+	})
+	converter.Use(plugin.GitHubFlavored())
+
+	markdown := converter.Convert(doc.Find("body"))
+
+	// Keep the page title unless the page already starts with it
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title != "" && !strings.HasPrefix(markdown, "# ") {
+		markdown = "# " + title + "\n\n" + markdown
+	}
+
+	return markdown + "\n", nil
+}
+
+// importHTMLImage stores an image referenced by a web page and returns its new
+// reference, or "" when the image stays where it is (remote URLs)
+func importHTMLImage(baseDir string, src string, attachments *attachmentWriter) (string, error) {
+	if strings.HasPrefix(src, "data:") {
+		header, payload, ok := strings.Cut(strings.TrimPrefix(src, "data:"), ",")
+		if !ok || !strings.HasSuffix(header, ";base64") {
+			return "", fmt.Errorf("unsupported data URI")
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", fmt.Errorf("invalid data URI: %w", err)
+		}
+		ext := ".bin"
+		if exts, _ := mime.ExtensionsByType(strings.TrimSuffix(header, ";base64")); len(exts) > 0 {
+			ext = exts[0]
+		}
+		return attachments.save("image"+ext, data)
+	}
+
+	src = strings.TrimPrefix(src, "file://")
+	if isRemoteURL(src) {
+		return "", nil
+	}
+
+	resolved, err := resolveImagePath(baseDir, strings.SplitN(src, "?", 2)[0])
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", err
+	}
+	return attachments.save(filepath.Base(resolved), data)
+}
+
+// xmlNode is a generic XML element used to walk WordprocessingML
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr returns the value of the attribute with the given local name
+func (n *xmlNode) attr(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with the given local name
+func (n *xmlNode) child(local string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// find returns every descendant element with the given local name
+func (n *xmlNode) find(local string) []*xmlNode {
+	var found []*xmlNode
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		if child.XMLName.Local == local {
+			found = append(found, child)
+		}
+		found = append(found, child.find(local)...)
+	}
+	return found
+}
+
+// docxReader converts a Word document to markdown
+type docxReader struct {
+	files       map[string]*zip.File
+	styles      map[string]string         // style ID -> lowercase style name
+	numFormats  map[string]map[int]string // numId -> level -> numFmt
+	rels        map[string]string         // relationship ID -> target
+	images      map[string]string         // media path -> markdown reference
+	attachments *attachmentWriter
+}
+
+// docxRun is a stretch of text with one formatting
+type docxRun struct {
+	text   string
+	bold   bool
+	italic bool
+	strike bool
+	code   bool
+	link   string
+	image  bool // text is already markdown
+}
+
+// convertDOCX converts a Word document to markdown: heading, quote and code
+// paragraph styles, bulleted and numbered lists, tables, links, emphasis and
+// images, which are extracted to the attachments folder
+func convertDOCX(path string, attachments *attachmentWriter) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	r := &docxReader{
+		files:       map[string]*zip.File{},
+		styles:      map[string]string{},
+		numFormats:  map[string]map[int]string{},
+		rels:        map[string]string{},
+		images:      map[string]string{},
+		attachments: attachments,
+	}
+	for _, f := range zr.File {
+		r.files[f.Name] = f
+	}
+
+	document, err := r.readXML("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	r.loadStyles()
+	r.loadNumbering()
+	r.loadRelationships()
+
+	body := document.child("body")
+	if body == nil {
+		return "", fmt.Errorf("%s has no document body", path)
+	}
+
+	var blocks []string
+	var code []string
+	flushCode := func() {
+		if len(code) > 0 {
+			blocks = append(blocks, "```\n"+strings.Join(code, "\n")+"\n```")
+			code = nil
+		}
+	}
+
+	for i := range body.Nodes {
+		node := &body.Nodes[i]
+		switch node.XMLName.Local {
+		case "p":
+			if r.isCodeParagraph(node) {
+				code = append(code, r.plainText(node))
+				continue
+			}
+			flushCode()
+			if block := r.convertParagraph(node); block != "" {
+				blocks = append(blocks, block)
+			}
+		case "tbl":
+			flushCode()
+			if block := r.convertTable(node); block != "" {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	flushCode()
+
+	// Consecutive list items form one list
+	var sb strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			if isListBlock(blocks[i-1]) && isListBlock(block) {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(block)
+	}
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// isListBlock reports whether a converted block is a list item
+func isListBlock(block string) bool {
+	trimmed := strings.TrimLeft(block, " ")
+	if strings.HasPrefix(trimmed, "- ") {
+		return true
+	}
+	number, _, ok := strings.Cut(trimmed, ". ")
+	_, err := strconv.Atoi(number)
+	return ok && err == nil
+}
+
+// readXML parses a part of the package
+func (r *docxReader) readXML(name string) (*xmlNode, error) {
+	f, ok := r.files[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var node xmlNode
+	if err := xml.NewDecoder(rc).Decode(&node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// loadStyles maps style IDs to their names, which are stable across languages
+func (r *docxReader) loadStyles() {
+	styles, err := r.readXML("word/styles.xml")
+	if err != nil {
+		return
+	}
+	for _, style := range styles.find("style") {
+		if name := style.child("name"); name != nil {
+			r.styles[style.attr("styleId")] = strings.ToLower(name.attr("val"))
+		}
+	}
+}
+
+// loadNumbering records whether each list level is bulleted or numbered
+func (r *docxReader) loadNumbering() {
+	numbering, err := r.readXML("word/numbering.xml")
+	if err != nil {
+		return
+	}
+
+	abstracts := map[string]map[int]string{}
+	for _, abstract := range numbering.find("abstractNum") {
+		levels := map[int]string{}
+		for _, lvl := range abstract.find("lvl") {
+			level, _ := strconv.Atoi(lvl.attr("ilvl"))
+			if format := lvl.child("numFmt"); format != nil {
+				levels[level] = format.attr("val")
+			}
+		}
+		abstracts[abstract.attr("abstractNumId")] = levels
+	}
+
+	for _, num := range numbering.find("num") {
+		if abstract := num.child("abstractNumId"); abstract != nil {
+			r.numFormats[num.attr("numId")] = abstracts[abstract.attr("val")]
+		}
+	}
+}
+
+// loadRelationships maps relationship IDs to link targets and media paths
+func (r *docxReader) loadRelationships() {
+	rels, err := r.readXML("word/_rels/document.xml.rels")
+	if err != nil {
+		return
+	}
+	for _, rel := range rels.find("Relationship") {
+		target := rel.attr("Target")
+		if rel.attr("TargetMode") != "External" {
+			// Absolute targets start at the package root, others at word/
+			if absolute, ok := strings.CutPrefix(target, "/"); ok {
+				target = path.Clean(absolute)
+			} else {
+				target = path.Join("word", target)
+			}
+		}
+		r.rels[rel.attr("Id")] = target
+	}
+}
+
+// paragraphStyle returns the lowercase name of a paragraph's style
+func (r *docxReader) paragraphStyle(p *xmlNode) string {
+	if props := p.child("pPr"); props != nil {
+		if style := props.child("pStyle"); style != nil {
+			id := style.attr("val")
+			if name, ok := r.styles[id]; ok {
+				return name
+			}
+			return strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// isCodeParagraph reports whether a paragraph uses a preformatted style
+func (r *docxReader) isCodeParagraph(p *xmlNode) bool {
+	style := r.paragraphStyle(p)
+	return strings.Contains(style, "code") || strings.Contains(style, "preformatted")
+}
+
+// convertParagraph converts a paragraph to a markdown block
+func (r *docxReader) convertParagraph(p *xmlNode) string {
+	text := strings.TrimSpace(r.inlineText(p))
+	if text == "" {
+		return ""
+	}
+
+	style := r.paragraphStyle(p)
+	switch {
+	case style == "title":
+		return "# " + text
+	case strings.HasPrefix(style, "heading "):
+		level, err := strconv.Atoi(strings.TrimPrefix(style, "heading "))
+		if err == nil && level >= 1 && level <= 6 {
+			return strings.Repeat("#", level) + " " + text
+		}
+	case strings.Contains(style, "quote"):
+		return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	}
+
+	if props := p.child("pPr"); props != nil {
+		if numPr := props.child("numPr"); numPr != nil {
+			level := 0
+			if ilvl := numPr.child("ilvl"); ilvl != nil {
+				level, _ = strconv.Atoi(ilvl.attr("val"))
+			}
+			numID := ""
+			if id := numPr.child("numId"); id != nil {
+				numID = id.attr("val")
+			}
+			// numId 0 switches numbering off
+			if numID != "" && numID != "0" {
+				marker := "- "
+				if format := r.numFormats[numID][level]; format != "" && format != "bullet" && format != "none" {
+					marker = "1. "
+				}
+				return strings.Repeat("   ", level) + marker + text
+			}
+		}
+	}
+
+	return text
+}
+
+// plainText returns the text of a paragraph without formatting
+func (r *docxReader) plainText(p *xmlNode) string {
+	var sb strings.Builder
+	var walk func(n *xmlNode)
+	walk = func(n *xmlNode) {
+		for i := range n.Nodes {
+			child := &n.Nodes[i]
+			switch child.XMLName.Local {
+			case "t":
+				sb.WriteString(child.Text)
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(p)
+	return sb.String()
+}
+
+// inlineText converts the runs of a paragraph to markdown
+func (r *docxReader) inlineText(p *xmlNode) string {
+	var runs []docxRun
+	r.collectRuns(p, "", &runs)
+
+	// Merge neighbouring runs with the same formatting so Word's run splits
+	// don't turn into "**a****b**"
+	var merged []docxRun
+	for _, run := range runs {
+		if n := len(merged); n > 0 && !run.image && !merged[n-1].image {
+			last := &merged[n-1]
+			if last.bold == run.bold && last.italic == run.italic && last.strike == run.strike &&
+				last.code == run.code && last.link == run.link {
+				last.text += run.text
+				continue
+			}
+		}
+		merged = append(merged, run)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(merged); i++ {
+		run := merged[i]
+		if run.image {
+			sb.WriteString(run.text)
+			continue
+		}
+
+		// Links wrap every run that shares the target
+		if run.link != "" {
+			var label strings.Builder
+			j := i
+			for ; j < len(merged) && merged[j].link == run.link && !merged[j].image; j++ {
+				label.WriteString(formatDOCXRun(merged[j]))
+			}
+			i = j - 1
+			fmt.Fprintf(&sb, "[%s](%s)", strings.TrimSpace(label.String()), run.link)
+			continue
+		}
+
+		sb.WriteString(formatDOCXRun(run))
+	}
+	return sb.String()
+}
+
+// collectRuns gathers the runs under n, inside the hyperlink target link
+func (r *docxReader) collectRuns(n *xmlNode, link string, runs *[]docxRun) {
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		switch child.XMLName.Local {
+		case "hyperlink":
+			target := ""
+			if id := child.attr("id"); id != "" {
+				target = r.rels[id]
+			} else if anchor := child.attr("anchor"); anchor != "" {
+				target = "#" + anchor
+			}
+			r.collectRuns(child, target, runs)
+
+		case "r":
+			run := docxRun{link: link}
+			if props := child.child("rPr"); props != nil {
+				run.bold = isDOCXToggleOn(props.child("b"))
+				run.italic = isDOCXToggleOn(props.child("i"))
+				run.strike = isDOCXToggleOn(props.child("strike"))
+				if style := props.child("rStyle"); style != nil {
+					name := strings.ToLower(r.styles[style.attr("val")] + style.attr("val"))
+					run.code = strings.Contains(name, "code") || strings.Contains(name, "verbatim")
+				}
+			}
+
+			var sb strings.Builder
+			for j := range child.Nodes {
+				part := &child.Nodes[j]
+				switch part.XMLName.Local {
+				case "t":
+					sb.WriteString(part.Text)
+				case "tab":
+					sb.WriteString(" ")
+				case "br", "cr":
+					sb.WriteString("\n")
+				case "drawing", "pict":
+					if sb.Len() > 0 {
+						run.text = sb.String()
+						*runs = append(*runs, run)
+						sb.Reset()
+					}
+					if image := r.convertImage(part); image != "" {
+						*runs = append(*runs, docxRun{text: image, image: true})
+					}
+				}
+			}
+			if sb.Len() > 0 {
+				run.text = sb.String()
+				*runs = append(*runs, run)
+			}
+
+		case "smartTag", "sdt", "sdtContent", "ins", "fldSimple":
+			r.collectRuns(child, link, runs)
+		}
+	}
+}
+
+// isDOCXToggleOn reports whether a toggle property such as <w:b/> is set
+func isDOCXToggleOn(n *xmlNode) bool {
+	if n == nil {
+		return false
+	}
+	value := n.attr("val")
+	return value == "" || value == "1" || value == "true" || value == "on"
+}
+
+// formatDOCXRun returns a run's text with markdown emphasis, keeping
+// surrounding spaces outside the markers
+func formatDOCXRun(run docxRun) string {
+	text := run.text
+	if run.code {
+		return "`" + text + "`"
+	}
+	text = escapeMarkdownText(text)
+	text = strings.ReplaceAll(text, "\n", "  \n")
+
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+
+	if run.strike {
+		trimmed = "~~" + trimmed + "~~"
+	}
+	if run.italic {
+		trimmed = "*" + trimmed + "*"
+	}
+	if run.bold {
+		trimmed = "**" + trimmed + "**"
+	}
+	return lead + trimmed + trail
+}
+
+// escapeMarkdownText escapes characters that markdown would read as syntax
+func escapeMarkdownText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+	return replacer.Replace(text)
+}
+
+// convertImage extracts the image in a drawing and returns its markdown
+func (r *docxReader) convertImage(drawing *xmlNode) string {
+	alt := ""
+	if props := drawing.find("docPr"); len(props) > 0 {
+		alt = props[0].attr("descr")
+	}
+
+	var relID string
+	if blips := drawing.find("blip"); len(blips) > 0 {
+		relID = blips[0].attr("embed")
+	} else if data := drawing.find("imagedata"); len(data) > 0 {
+		relID = data[0].attr("id")
+	}
+	target, ok := r.rels[relID]
+	if !ok {
+		return ""
+	}
+
+	ref, ok := r.images[target]
+	if !ok {
+		f, exists := r.files[target]
+		if !exists {
+			return ""
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ""
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return ""
+		}
+		ref, err = r.attachments.save(path.Base(target), data)
+		if err != nil {
+			fmt.Printf("Warning: Could not extract image %s: %v\n", target, err)
+			return ""
+		}
+		r.images[target] = ref
+	}
+
+	return fmt.Sprintf("![%s](%s)", escapeMarkdownText(alt), ref)
+}
+
+// convertTable converts a table to a GFM table with the first row as header
+func (r *docxReader) convertTable(tbl *xmlNode) string {
+	var rows [][]string
+	for i := range tbl.Nodes {
+		row := &tbl.Nodes[i]
+		if row.XMLName.Local != "tr" {
+			continue
+		}
+		var cells []string
+		for j := range row.Nodes {
+			cell := &row.Nodes[j]
+			if cell.XMLName.Local != "tc" {
+				continue
+			}
+			var parts []string
+			for _, p := range cell.find("p") {
+				if text := strings.TrimSpace(r.inlineText(p)); text != "" {
+					parts = append(parts, text)
+				}
+			}
+			text := strings.Join(parts, "<br>")
+			text = strings.ReplaceAll(text, "|", `\|`)
+			text = strings.ReplaceAll(text, "\n", "<br>")
+			cells = append(cells, text)
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, cells := range rows {
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for c := 0; c < columns; c++ {
+			text := ""
+			if c < len(cells) {
+				text = cells[c]
+			}
+			sb.WriteString(" " + text + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Header cells are bold in markdown already
+	for i, text := range rows[0] {
+		if strings.HasPrefix(text, "**") && strings.HasSuffix(text, "**") && len(text) > 4 {
+			rows[0][i] = text[2 : len(text)-2]
+		}
+	}
+
+	writeRow(rows[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+	for _, cells := range rows[1:] {
+		writeRow(cells)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
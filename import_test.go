@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportFileCleansNameAndUsesFolderTemplate(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "Q3: plan?.html")
+	writeTestFile(t, source, "<html><body><p>Hello <b>world</b></p></body></html>")
+	writeTestFile(t, filepath.Join(root, templatesDirName, "imported.md"), "# {{title}}\n\n{{content}}\n---\n")
+
+	app := NewApp()
+	app.workspacePath = root
+	if err := app.SetFolderTemplate(root, "imported"); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := app.ImportFile(source, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != root || strings.ContainsAny(filepath.Base(path), ":?") {
+		t.Errorf("imported as %s, want a cleaned-up name in %s", path, root)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := strings.TrimSuffix(filepath.Base(path), ".md")
+	want := "# " + title + "\n\nHello **world**\n\n---\n"
+	if string(data) != want {
+		t.Errorf("imported note reads %q, want %q", data, want)
+	}
+}
+
+func TestImportFileAppendsToTemplateWithoutContent(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "page.html")
+	writeTestFile(t, source, "<p>Body</p>")
+	writeTestFile(t, filepath.Join(root, templatesDirName, "tagged.md"), "tags: imported")
+
+	app := NewApp()
+	app.workspacePath = root
+	if err := app.SetFolderTemplate(root, "tagged"); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := app.ImportFile(source, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "tags: imported\nBody\n" {
+		t.Errorf("imported note reads %q", data)
+	}
+}
@@ -0,0 +1,546 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImportReport summarises a Notion or Obsidian import
+type ImportReport struct {
+	Source      string          `json:"source"`
+	TargetDir   string          `json:"targetDir"`
+	Notes       int             `json:"notes"`
+	Tables      int             `json:"tables"` // databases converted to markdown tables
+	Attachments int             `json:"attachments"`
+	Problems    []ImportProblem `json:"problems"`
+}
+
+// ImportProblem is something the importer could not convert
+type ImportProblem struct {
+	Path    string `json:"path"` // relative to the source
+	Message string `json:"message"`
+}
+
+// addProblem records a problem with the file at rel
+func (r *ImportReport) addProblem(rel string, format string, args ...any) {
+	r.Problems = append(r.Problems, ImportProblem{Path: rel, Message: fmt.Sprintf(format, args...)})
+}
+
+var (
+	// notionIDPattern matches the page ID Notion appends to exported names,
+	// either as 32 hex digits or as a hyphenated UUID
+	notionIDPattern = regexp.MustCompile(`\s+(?:[0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})(_all)?$`)
+
+	// markdownLinkPattern matches the target of inline links and images
+	markdownLinkPattern = regexp.MustCompile(`(!?\[[^\]]*\]\()(<[^>]*>|[^)\s]+)((?:\s+"[^"]*")?\))`)
+
+	// wikiLinkPattern matches Obsidian [[links]] and ![[embeds]]
+	wikiLinkPattern = regexp.MustCompile(`(!?)\[\[([^\]|#^]*)([#^][^\]|]*)?(\|[^\]]*)?\]\]`)
+)
+
+// notionEntry is a file from a Notion export
+type notionEntry struct {
+	source   string // path inside the export
+	target   string // slash path relative to the target directory
+	data     []byte
+	modified time.Time
+}
+
+// ImportNotion imports a Notion "Markdown & CSV" export zip into targetDir,
+// the current directory when empty. Page IDs are stripped from names, CSV
+// databases become markdown tables and links are pointed at the new names.
+func (a *App) ImportNotion(zipPath string, targetDir string) (ImportReport, error) {
+	if targetDir == "" {
+		targetDir = a.currentDir
+	}
+	report := ImportReport{Source: zipPath, TargetDir: targetDir}
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return report, fmt.Errorf("failed to open %s: %w", zipPath, err)
+	}
+	defer zr.Close()
+
+	var entries []*notionEntry
+	if err := readNotionZip(&zr.Reader, "", &entries, &report); err != nil {
+		return report, err
+	}
+	if len(entries) == 0 {
+		return report, fmt.Errorf("%s contains no files", zipPath)
+	}
+
+	// Prefer the "_all" export of a database over the filtered view
+	hasAll := map[string]bool{}
+	for _, entry := range entries {
+		if strings.HasSuffix(strings.TrimSuffix(entry.source, ".csv"), "_all") {
+			hasAll[notionIDPattern.ReplaceAllString(strings.TrimSuffix(entry.source, ".csv"), "")] = true
+		}
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		stem := strings.TrimSuffix(entry.source, ".csv")
+		if strings.HasSuffix(entry.source, ".csv") && !strings.HasSuffix(stem, "_all") && hasAll[notionIDPattern.ReplaceAllString(stem, "")] {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	entries = kept
+
+	// Work out clean target names, avoiding clashes with each other and with
+	// files already in the target directory
+	used := map[string]map[string]bool{}
+	usedIn := func(dir string) map[string]bool {
+		if names, ok := used[dir]; ok {
+			return names
+		}
+		names := map[string]bool{}
+		if existing, err := os.ReadDir(filepath.Join(targetDir, filepath.FromSlash(dir))); err == nil {
+			for _, e := range existing {
+				names[strings.ToLower(e.Name())] = true
+			}
+		}
+		used[dir] = names
+		return names
+	}
+
+	// A page "Title <id>.md" keeps its subpages in "Title <id>/", so a page and
+	// its folder share one clean name. Pages with the same title get different
+	// names, and so do their folders, instead of being merged.
+	stems := map[string]map[string]string{} // target dir -> source stem -> target stem
+	pageStem := func(dir string, sourceStem string) string {
+		// A database's "_all" export goes with the folder of its rows
+		if notionIDPattern.MatchString(sourceStem) {
+			sourceStem = strings.TrimSuffix(sourceStem, "_all")
+		}
+		if stems[dir] == nil {
+			stems[dir] = map[string]string{}
+		}
+		if stem, ok := stems[dir][sourceStem]; ok {
+			return stem
+		}
+		stem := uniqueNotionStem(cleanNotionStem(sourceStem), usedIn(dir))
+		stems[dir][sourceStem] = stem
+		return stem
+	}
+
+	renamed := map[string]string{} // source path -> target path
+	dirs := map[string]string{}    // source folder -> target folder
+	for _, entry := range entries {
+		parts := strings.Split(entry.source, "/")
+		dir := ""
+		for i := range parts[:len(parts)-1] {
+			source := strings.Join(parts[:i+1], "/")
+			target, ok := dirs[source]
+			if !ok {
+				target = path.Join(dir, pageStem(dir, parts[i]))
+				dirs[source] = target
+			}
+			dir = target
+		}
+
+		name := parts[len(parts)-1]
+		ext := path.Ext(name)
+		if strings.EqualFold(ext, ".md") || strings.EqualFold(ext, ".csv") {
+			name = pageStem(dir, strings.TrimSuffix(name, ext)) + ".md"
+		} else {
+			name = uniqueFileName(cleanNotionName(name), usedIn(dir))
+		}
+
+		entry.target = path.Join(dir, name)
+		renamed[entry.source] = entry.target
+	}
+
+	for _, entry := range entries {
+		data := entry.data
+		switch {
+		case strings.EqualFold(path.Ext(entry.source), ".csv"):
+			table, err := notionCSVToMarkdown(entry, renamed, &report)
+			if err != nil {
+				report.addProblem(entry.source, "could not convert database: %v", err)
+				// Keep the raw CSV next to where the table would have been
+				entry.target = strings.TrimSuffix(entry.target, ".md") + ".csv"
+				report.Attachments++
+				break
+			}
+			data = []byte(table)
+			report.Tables++
+			report.Notes++
+
+		case isMarkdownFile(entry.source):
+			data = []byte(rewriteNotionLinks(string(data), entry, renamed, &report))
+			report.Notes++
+
+		default:
+			report.Attachments++
+		}
+
+		if err := writeImportedFile(targetDir, entry.target, data, entry.modified); err != nil {
+			return report, err
+		}
+	}
+
+	sortImportProblems(report.Problems)
+	return report, nil
+}
+
+// readNotionZip collects the files in a Notion export. Large workspaces are
+// exported as a zip of zips, which are read recursively.
+func readNotionZip(zr *zip.Reader, prefix string, entries *[]*notionEntry, report *ImportReport) error {
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name, err := safeArchivePath(f.Name)
+		if err != nil {
+			report.addProblem(prefix+f.Name, "skipped: %v", err)
+			continue
+		}
+		if strings.HasPrefix(path.Base(name), ".") || strings.HasPrefix(name, "__MACOSX/") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+
+		if strings.EqualFold(path.Ext(name), ".zip") {
+			nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				report.addProblem(prefix+name, "could not open nested archive: %v", err)
+				continue
+			}
+			if err := readNotionZip(nested, prefix+name+"/", entries, report); err != nil {
+				return err
+			}
+			continue
+		}
+
+		*entries = append(*entries, &notionEntry{source: name, data: data, modified: f.Modified})
+	}
+	return nil
+}
+
+// safeArchivePath cleans a path from an archive and rejects absolute paths and
+// paths that would escape the extraction directory ("zip slip")
+func safeArchivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("absolute path %s", name)
+	}
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") || cleaned == "." {
+		return "", fmt.Errorf("path %s escapes the target directory", name)
+	}
+	return cleaned, nil
+}
+
+// cleanNotionName removes the page ID Notion appends to a file or folder name
+func cleanNotionName(name string) string {
+	ext := path.Ext(name)
+	return cleanNotionStem(strings.TrimSuffix(name, ext)) + ext
+}
+
+// cleanNotionStem strips the page ID from a name without its extension
+func cleanNotionStem(stem string) string {
+	if cleaned := strings.TrimSpace(notionIDPattern.ReplaceAllString(stem, "")); cleaned != "" {
+		return cleaned
+	}
+	return stem
+}
+
+// uniqueNotionStem returns stem, or stem with "-1", "-2" and so on added,
+// such that neither a folder nor a note by that name is taken in used, and
+// marks both taken
+func uniqueNotionStem(stem string, used map[string]bool) string {
+	candidate := stem
+	for i := 1; used[strings.ToLower(candidate)] || used[strings.ToLower(candidate+".md")]; i++ {
+		candidate = fmt.Sprintf("%s-%d", stem, i)
+	}
+	used[strings.ToLower(candidate)] = true
+	used[strings.ToLower(candidate+".md")] = true
+	return candidate
+}
+
+// rewriteNotionLinks points links between exported files at their new names.
+// Links to pages missing from the export are reported.
+func rewriteNotionLinks(content string, entry *notionEntry, renamed map[string]string, report *ImportReport) string {
+	return markdownLinkPattern.ReplaceAllStringFunc(content, func(match string) string {
+		groups := markdownLinkPattern.FindStringSubmatch(match)
+		prefix, target, suffix := groups[1], strings.Trim(groups[2], "<>"), groups[3]
+
+		if strings.Contains(target, "notion.so/") {
+			report.addProblem(entry.source, "link to a Notion page outside the export: %s", target)
+			return match
+		}
+		if target == "" || strings.HasPrefix(target, "#") || isRemoteURL(target) {
+			return match
+		}
+
+		newTarget, ok := resolveNotionTarget(target, entry, renamed)
+		if !ok {
+			report.addProblem(entry.source, "link target not found in export: %s", target)
+			return match
+		}
+		return prefix + newTarget + suffix
+	})
+}
+
+// resolveNotionTarget maps a link from entry to the new relative, URL-escaped path of its target
+func resolveNotionTarget(target string, entry *notionEntry, renamed map[string]string) (string, bool) {
+	ref, fragment, _ := strings.Cut(target, "#")
+	decoded, err := url.PathUnescape(ref)
+	if err != nil {
+		decoded = ref
+	}
+
+	source := path.Clean(path.Join(path.Dir(entry.source), decoded))
+	newPath, ok := renamed[source]
+	if !ok && strings.HasSuffix(source, ".csv") {
+		// Links to a filtered view follow the "_all" export that replaced it
+		newPath, ok = renamed[strings.TrimSuffix(source, ".csv")+"_all.csv"]
+	}
+	if !ok {
+		return "", false
+	}
+
+	result := escapeSlashPath(relativeSlashPath(path.Dir(entry.target), newPath))
+	if fragment != "" {
+		result += "#" + fragment
+	}
+	return result, true
+}
+
+// escapeSlashPath URL-escapes each element of a slash path for use as a link target
+func escapeSlashPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// relativeSlashPath returns target relative to the directory from, both slash paths
+func relativeSlashPath(from string, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(from), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// notionCSVToMarkdown converts an exported database to a note with a markdown table.
+// Cells naming other exported pages link to them.
+func notionCSVToMarkdown(entry *notionEntry, renamed map[string]string, report *ImportReport) (string, error) {
+	data := bytes.TrimPrefix(entry.data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("database is empty")
+	}
+
+	// Rows are exported as pages in a folder named after the database
+	rowDir := strings.TrimSuffix(strings.TrimSuffix(entry.source, ".csv"), "_all")
+	rowPages := map[string]string{}
+	for source, target := range renamed {
+		if path.Dir(source) == rowDir && isMarkdownFile(source) {
+			rowPages[strings.TrimSuffix(cleanNotionName(path.Base(source)), path.Ext(source))] = target
+		}
+	}
+
+	columns := 0
+	for _, record := range records {
+		if len(record) > columns {
+			columns = len(record)
+		}
+	}
+
+	title := strings.TrimSuffix(path.Base(entry.target), ".md")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+
+	for i, record := range records {
+		sb.WriteString("|")
+		for c := 0; c < columns; c++ {
+			cell := ""
+			if c < len(record) {
+				cell = strings.TrimSpace(record[c])
+			}
+			cell = strings.ReplaceAll(cell, "|", `\|`)
+			cell = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "\n"), "\n", "<br>")
+			if i > 0 && c == 0 {
+				if page, ok := rowPages[cell]; ok {
+					cell = fmt.Sprintf("[%s](%s)", cell, escapeSlashPath(relativeSlashPath(path.Dir(entry.target), page)))
+				}
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+
+	if len(records) == 1 {
+		report.addProblem(entry.source, "database has no rows")
+	}
+
+	return sb.String(), nil
+}
+
+// ImportObsidian copies an Obsidian vault into targetDir, the current
+// directory when empty. Wiki links, embeds and attachments are kept as they
+// are; broken links and Obsidian-only features are listed in the report.
+func (a *App) ImportObsidian(vaultDir string, targetDir string) (ImportReport, error) {
+	if targetDir == "" {
+		targetDir = a.currentDir
+	}
+	report := ImportReport{Source: vaultDir, TargetDir: targetDir}
+
+	info, err := os.Stat(vaultDir)
+	if err != nil {
+		return report, fmt.Errorf("failed to get file info for %s: %w", vaultDir, err)
+	}
+	if !info.IsDir() {
+		return report, fmt.Errorf("path %s is not a directory", vaultDir)
+	}
+
+	absVault, _ := filepath.Abs(vaultDir)
+	absTarget, _ := filepath.Abs(targetDir)
+	if isSameOrChildPath(absTarget, absVault) {
+		return report, fmt.Errorf("cannot import a vault into itself")
+	}
+
+	// Collect the vault first so links can be checked against every file
+	var files []string
+	byName := map[string]bool{}
+	byPath := map[string]bool{}
+	err = walkWorkspace(vaultDir, nil, func(p string, rel string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		files = append(files, rel)
+		byPath[strings.ToLower(rel)] = true
+		byName[strings.ToLower(path.Base(rel))] = true
+		byName[strings.ToLower(strings.TrimSuffix(path.Base(rel), path.Ext(rel)))] = true
+		byPath[strings.ToLower(strings.TrimSuffix(rel, path.Ext(rel)))] = true
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to read vault %s: %w", vaultDir, err)
+	}
+
+	for _, rel := range files {
+		dest := filepath.Join(targetDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(dest); err == nil {
+			report.addProblem(rel, "skipped: a file with this name already exists in the target")
+			continue
+		}
+
+		src := filepath.Join(vaultDir, filepath.FromSlash(rel))
+		if err := copyFile(src, dest); err != nil {
+			return report, err
+		}
+
+		switch {
+		case strings.HasSuffix(strings.ToLower(rel), ".excalidraw.md"):
+			report.addProblem(rel, "Excalidraw drawings are copied but can't be displayed")
+			report.Attachments++
+		case isMarkdownFile(rel):
+			report.Notes++
+			content, err := os.ReadFile(src)
+			if err != nil {
+				return report, fmt.Errorf("failed to read %s: %w", src, err)
+			}
+			checkObsidianNote(rel, string(content), byName, byPath, &report)
+		case strings.EqualFold(path.Ext(rel), ".canvas"):
+			report.addProblem(rel, "canvas files are copied but can't be displayed")
+			report.Attachments++
+		default:
+			report.Attachments++
+		}
+	}
+
+	sortImportProblems(report.Problems)
+	return report, nil
+}
+
+// checkObsidianNote reports wiki links that don't resolve and syntax that only
+// Obsidian plugins understand
+func checkObsidianNote(rel string, content string, byName map[string]bool, byPath map[string]bool, report *ImportReport) {
+	for _, groups := range wikiLinkPattern.FindAllStringSubmatch(content, -1) {
+		target := strings.TrimSpace(groups[2])
+		if target == "" {
+			// A link to a heading or block in the same note
+			continue
+		}
+		lower := strings.ToLower(target)
+		relative := strings.ToLower(path.Join(path.Dir(rel), target))
+		if byName[lower] || byPath[lower] || byPath[relative] {
+			continue
+		}
+		kind := "link"
+		if groups[1] == "!" {
+			kind = "embed"
+		}
+		report.addProblem(rel, "%s target not found in vault: %s", kind, target)
+	}
+
+	for _, lang := range []string{"dataview", "dataviewjs", "tasks", "query"} {
+		if strings.Contains(content, "```"+lang) {
+			report.addProblem(rel, "%s blocks need an Obsidian plugin and are kept as code", lang)
+		}
+	}
+	if strings.Contains(content, "%%") {
+		report.addProblem(rel, "Obsidian %%%%comments%%%% are kept as text")
+	}
+}
+
+// writeImportedFile writes data to rel under targetDir and sets its modification time
+func writeImportedFile(targetDir string, rel string, data []byte, modified time.Time) error {
+	dest := filepath.Join(targetDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dest, err)
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if !modified.IsZero() {
+		if err := os.Chtimes(dest, modified, modified); err != nil {
+			return fmt.Errorf("failed to set modification time of %s: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+// sortImportProblems orders problems by path, keeping each file's in report order
+func sortImportProblems(problems []ImportProblem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// lintConfigFileName is the per-workspace file configuring the linter
+const lintConfigFileName = ".markdownslint.json"
+
+// Lint severities
+const (
+	lintSeverityError   = "error"
+	lintSeverityWarning = "warning"
+	lintSeverityOff     = "off"
+)
+
+// Lint rule IDs
+const (
+	lintHeadingIncrement   = "heading-increment"    // headings go down one level at a time
+	lintTrailingSpaces     = "no-trailing-spaces"   // no spaces at the end of lines, except a two-space line break
+	lintListMarkerStyle    = "list-marker-style"    // bullet lists use one marker
+	lintBareURLs           = "no-bare-urls"         // URLs are written as links or <autolinks>
+	lintFencedCodeLanguage = "fenced-code-language" // code fences name their language
+	lintDuplicateHeadings  = "no-duplicate-heading" // no two headings have the same text
+)
+
+// lintRules lists every rule
+var lintRules = []string{
+	lintHeadingIncrement,
+	lintTrailingSpaces,
+	lintListMarkerStyle,
+	lintBareURLs,
+	lintFencedCodeLanguage,
+	lintDuplicateHeadings,
+}
+
+// LintIssue is a problem found by the linter
+type LintIssue struct {
+	Path     string `json:"path"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Line     int    `json:"line"`     // 1-based
+	Column   int    `json:"column"`   // 1-based, in characters
+	Message  string `json:"message"`
+}
+
+// LintRuleConfig configures one rule in the lint config file
+type LintRuleConfig struct {
+	Severity string `json:"severity"` // "error", "warning" or "off"
+	// Style is the marker list-marker-style requires: "-", "*" or "+", or
+	// "consistent" (the default) for whichever each note uses first
+	Style string `json:"style,omitempty"`
+}
+
+// LintConfig is the content of the lint config file. Rules left out keep
+// their defaults; a rule may also be given as just its severity, as in
+// {"rules": {"no-bare-urls": "off"}}.
+type LintConfig struct {
+	Rules map[string]LintRuleConfig `json:"rules"`
+}
+
+// UnmarshalJSON accepts a severity string in place of a rule object
+func (c *LintRuleConfig) UnmarshalJSON(data []byte) error {
+	var severity string
+	if err := json.Unmarshal(data, &severity); err == nil {
+		c.Severity = severity
+		return nil
+	}
+	type plain LintRuleConfig
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// defaultLintConfig enables every rule as a warning
+func defaultLintConfig() LintConfig {
+	config := LintConfig{Rules: map[string]LintRuleConfig{}}
+	for _, rule := range lintRules {
+		config.Rules[rule] = LintRuleConfig{Severity: lintSeverityWarning}
+	}
+	config.Rules[lintListMarkerStyle] = LintRuleConfig{Severity: lintSeverityWarning, Style: "consistent"}
+	return config
+}
+
+// loadLintConfig reads the nearest lint config file in dir or its parents,
+// stopping at stopDir when it isn't "", over the defaults
+func loadLintConfig(dir string, stopDir string) (LintConfig, error) {
+	return newLintConfigCache(stopDir).configFor(dir)
+}
+
+// lintConfigCache resolves the nearest lint config for many folders, reading
+// each config file once. Folders in a workspace can each have their own.
+type lintConfigCache struct {
+	stopDir string
+	configs map[string]LintConfig // folder to the config that applies in it
+}
+
+// newLintConfigCache creates a cache that looks no higher than stopDir, or up
+// to the file system root when stopDir is ""
+func newLintConfigCache(stopDir string) *lintConfigCache {
+	if stopDir != "" {
+		stopDir = filepath.Clean(stopDir)
+	}
+	return &lintConfigCache{stopDir: stopDir, configs: map[string]LintConfig{}}
+}
+
+// configFor returns the config from the nearest lint config file in dir or its parents
+func (c *lintConfigCache) configFor(dir string) (LintConfig, error) {
+	config := defaultLintConfig()
+	var visited []string
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		if cached, ok := c.configs[current]; ok {
+			config = cached
+			break
+		}
+		visited = append(visited, current)
+
+		configPath := filepath.Join(current, lintConfigFileName)
+		data, err := os.ReadFile(configPath)
+		if err == nil {
+			if config, err = parseLintConfig(data, config); err != nil {
+				return config, fmt.Errorf("%s: %w", configPath, err)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return config, fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+		if current == c.stopDir || filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	for _, folder := range visited {
+		c.configs[folder] = config
+	}
+	return config, nil
+}
+
+// parseLintConfig applies a lint config file over config
+func parseLintConfig(data []byte, config LintConfig) (LintConfig, error) {
+	var file LintConfig
+	if err := json.Unmarshal(data, &file); err != nil {
+		return config, fmt.Errorf("failed to parse %s: %w", lintConfigFileName, err)
+	}
+
+	for rule, ruleConfig := range file.Rules {
+		current, ok := config.Rules[rule]
+		if !ok {
+			return config, fmt.Errorf("unknown lint rule %q in %s", rule, lintConfigFileName)
+		}
+		switch ruleConfig.Severity {
+		case "":
+		case lintSeverityError, lintSeverityWarning, lintSeverityOff:
+			current.Severity = ruleConfig.Severity
+		default:
+			return config, fmt.Errorf("unknown severity %q for %s in %s", ruleConfig.Severity, rule, lintConfigFileName)
+		}
+		switch ruleConfig.Style {
+		case "":
+		case "consistent", "-", "*", "+":
+			current.Style = ruleConfig.Style
+		default:
+			return config, fmt.Errorf("unknown style %q for %s in %s", ruleConfig.Style, rule, lintConfigFileName)
+		}
+		config.Rules[rule] = current
+	}
+	return config, nil
+}
+
+// LintFile checks a note against the workspace's lint rules
+func (a *App) LintFile(path string) ([]LintIssue, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+
+	config, err := loadLintConfig(filepath.Dir(path), a.workspaceRoot())
+	if err != nil {
+		return nil, err
+	}
+	return lintFile(path, config)
+}
+
+// LintWorkspace checks every note in the workspace that the ignore rules keep
+func (a *App) LintWorkspace() ([]LintIssue, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return nil, fmt.Errorf("no workspace is open")
+	}
+	configs := newLintConfigCache(root)
+
+	issues := []LintIssue{}
+	err := walkNotes(root, nil, func(path string, rel string, d fs.DirEntry) error {
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+		config, err := configs.configFor(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		fileIssues, err := lintFile(path, config)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lint workspace %s: %w", root, err)
+	}
+	return issues, nil
+}
+
+// lintFile reads and lints one note
+func lintFile(path string, config LintConfig) ([]LintIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return lintContent(path, string(data), config), nil
+}
+
+var (
+	// listItemPattern matches a bullet list item, capturing what comes before the marker
+	listItemPattern = regexp.MustCompile(`^(\s*(?:>\s*)*)([-*+])(?:\s|$)`)
+	// thematicBreakPattern matches a horizontal rule, which can look like a list item
+	thematicBreakPattern = regexp.MustCompile(`^\s*(?:>\s*)*([-*_])(?:\s*([-*_])){2,}\s*$`)
+	// bareURLPattern matches a URL in text
+	bareURLPattern = regexp.MustCompile(`\b(?:https?://|www\.)[^\s<>]*[^\s<>.,;:!?)\]'"]`)
+	// linkedURLPatterns match the places a URL is fine: code spans, <autolinks>,
+	// link destinations, reference definitions and HTML attributes
+	linkedURLPatterns = []*regexp.Regexp{
+		regexp.MustCompile("``.*?``|`[^`]*`"),
+		regexp.MustCompile(`<[^<>\s]+>`),
+		regexp.MustCompile(`\]\([^)]*\)`),
+		regexp.MustCompile(`^\s*\[[^\]]+\]:\s*\S+`),
+		regexp.MustCompile(`\b(?:href|src)\s*=\s*("[^"]*"|'[^']*')`),
+		regexp.MustCompile(`\[[^\]]*\]`),
+	}
+)
+
+// lintContent checks content against the enabled rules and returns the issues in line order
+func lintContent(path string, content string, config LintConfig) []LintIssue {
+	issues := []LintIssue{}
+	report := func(rule string, line int, column int, message string) {
+		severity := config.Rules[rule].Severity
+		if severity == lintSeverityOff || severity == "" {
+			return
+		}
+		issues = append(issues, LintIssue{
+			Path:     path,
+			Rule:     rule,
+			Severity: severity,
+			Line:     line,
+			Column:   column,
+			Message:  message,
+		})
+	}
+
+	// Heading rules work from the parsed outline, so code blocks and front matter don't count
+	previousLevel := 0
+	seenHeadings := map[string]int{}
+	for _, heading := range outlineHeadings(content) {
+		if previousLevel > 0 && heading.Level > previousLevel+1 {
+			report(lintHeadingIncrement, heading.Line, 1,
+				fmt.Sprintf("heading level %d follows level %d; expected level %d at most", heading.Level, previousLevel, previousLevel+1))
+		}
+		previousLevel = heading.Level
+
+		key := strings.ToLower(heading.Text)
+		if first, ok := seenHeadings[key]; ok {
+			report(lintDuplicateHeadings, heading.Line, 1,
+				fmt.Sprintf("heading %q repeats the one on line %d", heading.Text, first))
+		} else {
+			seenHeadings[key] = heading.Line
+		}
+	}
+
+	listStyle := config.Rules[lintListMarkerStyle].Style
+	if listStyle == "" {
+		listStyle = "consistent"
+	}
+	lines := markdownBodyLines(content)
+	for i, line := range lines {
+		lineNumber := i + 1
+		if line.frontMatter {
+			continue
+		}
+
+		if line.fenceStart {
+			trimmed := strings.TrimLeft(line.text, " ")
+			info := strings.TrimSpace(strings.TrimLeft(trimmed, trimmed[:1]))
+			if info == "" {
+				report(lintFencedCodeLanguage, lineNumber, len(line.text)-len(trimmed)+1, "code fence has no language")
+			}
+		}
+		if line.code {
+			continue
+		}
+
+		// Two spaces after text are a line break, which is intended
+		trailing := len(line.text) - len(strings.TrimRight(line.text, " \t"))
+		body := strings.TrimRight(line.text, " \t")
+		lineBreak := trailing == 2 && strings.HasSuffix(line.text, "  ") && body != "" &&
+			i+1 < len(lines) && strings.TrimSpace(lines[i+1].text) != ""
+		if trailing > 0 && !lineBreak {
+			report(lintTrailingSpaces, lineNumber, utf8.RuneCountInString(body)+1,
+				fmt.Sprintf("%d trailing space(s)", trailing))
+		}
+
+		if match := listItemPattern.FindStringSubmatchIndex(line.text); match != nil && !thematicBreakPattern.MatchString(line.text) {
+			marker := line.text[match[4]:match[5]]
+			if listStyle == "consistent" {
+				listStyle = marker
+			}
+			if marker != listStyle {
+				report(lintListMarkerStyle, lineNumber, utf8.RuneCountInString(line.text[:match[4]])+1,
+					fmt.Sprintf("list marker %q should be %q", marker, listStyle))
+			}
+		}
+
+		// Blank out the places URLs belong so only bare ones are left
+		masked := []byte(line.text)
+		for _, pattern := range linkedURLPatterns {
+			for _, loc := range pattern.FindAllIndex(masked, -1) {
+				for j := loc[0]; j < loc[1]; j++ {
+					masked[j] = ' '
+				}
+			}
+		}
+		for _, loc := range bareURLPattern.FindAllIndex(masked, -1) {
+			report(lintBareURLs, lineNumber, utf8.RuneCountInString(line.text[:loc[0]])+1,
+				fmt.Sprintf("bare URL %s; use <%s> or a link", line.text[loc[0]:loc[1]], line.text[loc[0]:loc[1]]))
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintUsesNearestConfigAndIgnoreRules(t *testing.T) {
+	root := t.TempDir()
+	trailing := "Text   \n"
+	writeTestFile(t, filepath.Join(root, lintConfigFileName), `{"rules": {"no-trailing-spaces": {"severity": "off"}}}`)
+	writeTestFile(t, filepath.Join(root, "strict", lintConfigFileName), `{"rules": {"no-trailing-spaces": {"severity": "error"}}}`)
+	writeTestFile(t, filepath.Join(root, ignoreFileName), "drafts/\n")
+	writeTestFile(t, filepath.Join(root, "loose", "note.md"), trailing)
+	writeTestFile(t, filepath.Join(root, "strict", "deeper", "note.md"), trailing)
+	writeTestFile(t, filepath.Join(root, "drafts", lintConfigFileName), `{"rules": {"no-trailing-spaces": {"severity": "error"}}}`)
+	writeTestFile(t, filepath.Join(root, "drafts", "note.md"), trailing)
+
+	strictNote := filepath.Join(root, "strict", "deeper", "note.md")
+	check := func(name string, issues []LintIssue) {
+		t.Helper()
+		if len(issues) != 1 || issues[0].Path != strictNote || issues[0].Severity != lintSeverityError {
+			t.Errorf("%s found %+v, want one error in %s", name, issues, strictNote)
+		}
+	}
+
+	app := NewApp()
+	app.workspacePath = root
+	issues, err := app.LintWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("LintWorkspace", issues)
+
+	issues, err = app.LintFile(strictNote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("LintFile", issues)
+
+	// The command line finds the same issue and skips the ignored drafts
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	code := runLintCommand(app, []string{"-json", root})
+	os.Stdout = stdout
+	writer.Close()
+	issues = nil
+	if err := json.NewDecoder(reader).Decode(&issues); err != nil {
+		t.Fatal(err)
+	}
+	check("lint command", issues)
+	if code != 1 {
+		t.Errorf("lint command exited with %d, want 1 for the one issue", code)
+	}
+}
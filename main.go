@@ -76,11 +76,16 @@ func (h *FileLoader) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 }
 
 func main() {
+	// Run a command line subcommand instead of the GUI when one is given
+	if handled, code := runCLI(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	appOptions := &options.App{
 		Title:     "markdowns",
 		Width:     1024,
 		Frameless: false,
@@ -91,6 +96,9 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnDomReady:       app.domReady,
+		OnBeforeClose:    app.beforeClose,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -98,7 +106,12 @@ func main() {
 			TitleBar:             mac.TitleBarHiddenInset(),
 			WebviewIsTransparent: false,
 		},
-	})
+	}
+
+	// Restore the saved window size and maximized state
+	applyWindowOptions(appOptions)
+
+	err := wails.Run(appOptions)
 
 	if err != nil {
 		println("Error:", err.Error())
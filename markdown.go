@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdownDocument is a parsed note ready to be rendered or walked
+type markdownDocument struct {
+	Path        string
+	Source      []byte // body without front matter
+	FrontMatter map[string]string
+	Root        ast.Node
+}
+
+// newMarkdown returns a CommonMark + GFM converter with footnotes and
+// syntax highlighting. codeStyle is a chroma style name, "" for the default.
+func newMarkdown(codeStyle string) goldmark.Markdown {
+	if codeStyle == "" {
+		codeStyle = "github"
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(codeStyle),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+		goldmark.WithRendererOptions(
+			html.WithUnsafe(),
+		),
+	)
+}
+
+// parseMarkdownFile reads and parses a note
+func parseMarkdownFile(md goldmark.Markdown, path string) (*markdownDocument, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	frontMatter, body := parseFrontMatter(string(content))
+	source := []byte(body)
+
+	return &markdownDocument{
+		Path:        path,
+		Source:      source,
+		FrontMatter: frontMatter,
+		Root:        md.Parser().Parse(text.NewReader(source)),
+	}, nil
+}
+
+// render renders the document body to HTML
+func (d *markdownDocument) render(md goldmark.Markdown) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, d.Source, d.Root); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", d.Path, err)
+	}
+	return buf.String(), nil
+}
+
+// title returns the front matter title, the first heading, or the file name
+func (d *markdownDocument) title() string {
+	if title := d.FrontMatter["title"]; title != "" {
+		return title
+	}
+
+	var heading string
+	ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			heading = nodeText(h, d.Source)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if heading != "" {
+		return heading
+	}
+
+	return strings.TrimSuffix(filepath.Base(d.Path), filepath.Ext(d.Path))
+}
+
+// images returns every image node in the document
+func (d *markdownDocument) images() []*ast.Image {
+	var images []*ast.Image
+	ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			images = append(images, img)
+		}
+		return ast.WalkContinue, nil
+	})
+	return images
+}
+
+// nodeText returns the plain text content of a node
+func nodeText(n ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := child.(type) {
+		case *ast.Text:
+			buf.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(t.Value)
+		case *ast.CodeSpan:
+			for c := t.FirstChild(); c != nil; c = c.NextSibling() {
+				if seg, ok := c.(*ast.Text); ok {
+					buf.Write(seg.Segment.Value(source))
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(buf.String())
+}
+
+// parseFrontMatter splits a leading "---" YAML block from the body.
+// Only flat "key: value" pairs are read; nested values are ignored.
+func parseFrontMatter(content string) (map[string]string, string) {
+	frontMatter := map[string]string{}
+
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return frontMatter, content
+	}
+
+	rest := normalized[4:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return frontMatter, content
+	}
+
+	for _, line := range strings.Split(rest[:end], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		value = strings.Trim(value, `"'`)
+		frontMatter[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	body := rest[end+4:]
+	// Drop the rest of the closing delimiter line
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		body = body[newline+1:]
+	} else {
+		body = ""
+	}
+
+	return frontMatter, body
+}
+
+// isRemoteURL reports whether ref points outside the local file system
+func isRemoteURL(ref string) bool {
+	lower := strings.ToLower(ref)
+	for _, prefix := range []string{"http://", "https://", "data:", "mailto:", "//"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveImagePath resolves a possibly URL-encoded image reference against baseDir
+// and verifies that the file exists
+func resolveImagePath(baseDir string, relativePath string) (string, error) {
+	// Decode URL-encoded path
+	decodedPath, err := url.PathUnescape(relativePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode path: %w", err)
+	}
+
+	// If path is already absolute, return as is
+	if filepath.IsAbs(decodedPath) {
+		return decodedPath, nil
+	}
+
+	// Resolve relative path to absolute
+	absolutePath := filepath.Join(baseDir, decodedPath)
+	absolutePath = filepath.Clean(absolutePath)
+
+	// Verify file exists
+	if _, err := os.Stat(absolutePath); err != nil {
+		return "", fmt.Errorf("image file not found: %w", err)
+	}
+
+	return absolutePath, nil
+}
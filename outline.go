@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// OutlineHeading is a heading in a note's outline with the headings nested under it
+type OutlineHeading struct {
+	Level    int               `json:"level"`
+	Text     string            `json:"text"`
+	Slug     string            `json:"slug"`    // GitHub-compatible anchor, without the "#"
+	Line     int               `json:"line"`    // 1-based line of the heading in the file
+	EndLine  int               `json:"endLine"` // last line of the section, before the next heading at this level or above
+	Children []*OutlineHeading `json:"children"`
+}
+
+// GetOutline returns the heading tree of a note. Headings inside code blocks
+// and front matter are ignored.
+func (a *App) GetOutline(path string) ([]*OutlineHeading, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	return buildOutline(string(content)), nil
+}
+
+// buildOutline parses content and nests its headings by level. A heading that
+// skips levels is placed under the nearest shallower heading.
+func buildOutline(content string) []*OutlineHeading {
+	headings := outlineHeadings(content)
+	totalLines := strings.Count(content, "\n") + 1
+	if strings.HasSuffix(content, "\n") {
+		totalLines--
+	}
+
+	roots := []*OutlineHeading{}
+	var stack []*OutlineHeading
+	for i, heading := range headings {
+		// The section runs until the next heading at the same level or above
+		heading.EndLine = totalLines
+		for _, next := range headings[i+1:] {
+			if next.Level <= heading.Level {
+				heading.EndLine = next.Line - 1
+				break
+			}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+	}
+
+	return roots
+}
+
+// outlineHeadings returns the headings of content in document order, without nesting
+func outlineHeadings(content string) []*OutlineHeading {
+	_, body := parseFrontMatter(content)
+	// Lines taken by the front matter, so line numbers refer to the whole file
+	offset := strings.Count(content, "\n") - strings.Count(body, "\n")
+
+	source := []byte(body)
+	root := newMarkdown("").Parser().Parse(text.NewReader(source))
+
+	slugs := newSlugger()
+	var headings []*OutlineHeading
+	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		// An empty heading has no text to navigate to
+		if h.Lines().Len() == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+
+		start := h.Lines().At(0).Start
+		headingText := nodeText(h, source)
+		headings = append(headings, &OutlineHeading{
+			Level:    h.Level,
+			Text:     headingText,
+			Slug:     slugs.slug(headingText),
+			Line:     offset + strings.Count(body[:start], "\n") + 1,
+			Children: []*OutlineHeading{},
+		})
+		return ast.WalkSkipChildren, nil
+	})
+
+	return headings
+}
+
+// slugger generates GitHub-compatible heading anchors, numbering repeats
+// the way GitHub does ("intro", "intro-1", "intro-2")
+type slugger struct {
+	seen map[string]bool
+}
+
+func newSlugger() *slugger {
+	return &slugger{seen: map[string]bool{}}
+}
+
+// slug returns the anchor for a heading, unique within this slugger
+func (s *slugger) slug(heading string) string {
+	base := githubSlug(heading)
+	slug := base
+	for i := 1; s.seen[slug]; i++ {
+		slug = base + "-" + strconv.Itoa(i)
+	}
+	s.seen[slug] = true
+	return slug
+}
+
+// githubSlug lowercases heading text, drops punctuation and turns each space
+// into a hyphen, as GitHub does for heading anchors
+func githubSlug(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
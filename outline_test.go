@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// flatOutline lists an outline depth first, without the children
+func flatOutline(headings []*OutlineHeading) []OutlineHeading {
+	var flat []OutlineHeading
+	for _, h := range headings {
+		flat = append(flat, OutlineHeading{Level: h.Level, Text: h.Text, Slug: h.Slug, Line: h.Line, EndLine: h.EndLine})
+		flat = append(flat, flatOutline(h.Children)...)
+	}
+	return flat
+}
+
+func TestBuildOutline(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		roots   int
+		want    []OutlineHeading
+	}{
+		{
+			name:    "front matter and fenced code are skipped",
+			roots:   1,
+			content: "---\n# yaml comment\ntitle: x\n---\n# Intro\n```sh\n# shell comment\n```\n~~~\n## tilde\n~~~\n## Usage\n",
+			want: []OutlineHeading{
+				{Level: 1, Text: "Intro", Slug: "intro", Line: 5, EndLine: 12},
+				{Level: 2, Text: "Usage", Slug: "usage", Line: 12, EndLine: 12},
+			},
+		},
+		{
+			name:    "repeated headings are numbered",
+			roots:   2,
+			content: "# Intro\n## Notes\n# Intro\n## Notes\n## Intro 1\n### Intro-1!\n",
+			want: []OutlineHeading{
+				{Level: 1, Text: "Intro", Slug: "intro", Line: 1, EndLine: 2},
+				{Level: 2, Text: "Notes", Slug: "notes", Line: 2, EndLine: 2},
+				{Level: 1, Text: "Intro", Slug: "intro-1", Line: 3, EndLine: 6},
+				{Level: 2, Text: "Notes", Slug: "notes-1", Line: 4, EndLine: 4},
+				{Level: 2, Text: "Intro 1", Slug: "intro-1-1", Line: 5, EndLine: 6},
+				{Level: 3, Text: "Intro-1!", Slug: "intro-1-2", Line: 6, EndLine: 6},
+			},
+		},
+		{
+			name:    "skipped levels nest under the nearest shallower heading",
+			roots:   2,
+			content: "### Deep\n# Top\n### Skipped\nSetext\n------\ntext",
+			want: []OutlineHeading{
+				{Level: 3, Text: "Deep", Slug: "deep", Line: 1, EndLine: 1},
+				{Level: 1, Text: "Top", Slug: "top", Line: 2, EndLine: 6},
+				{Level: 3, Text: "Skipped", Slug: "skipped", Line: 3, EndLine: 3},
+				{Level: 2, Text: "Setext", Slug: "setext", Line: 4, EndLine: 6},
+			},
+		},
+	} {
+		outline := buildOutline(tc.content)
+		if len(outline) != tc.roots {
+			t.Errorf("%s: %d top-level headings, want %d", tc.name, len(outline), tc.roots)
+		}
+		if got := flatOutline(outline); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: outline =\n%+v\nwant\n%+v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// appDirName is the directory name used under the XDG base directories
+	appDirName = "markdowns"
+
+	// homeEnvVar overrides every config and data location when set
+	homeEnvVar = "MARKDOWNS_HOME"
+
+	// portableEnvVar enables portable mode when set to a truthy value
+	portableEnvVar = "MARKDOWNS_PORTABLE"
+
+	// portableMarker enables portable mode when present next to the binary
+	portableMarker = "markdowns.portable"
+
+	// portableDirName is the directory next to the binary used in portable mode
+	portableDirName = "markdowns-data"
+
+	// legacyDirName is the pre-XDG config directory under the user's home
+	legacyDirName = ".markdowns"
+)
+
+var migrateOnce sync.Once
+
+// getBaseOverride returns the root directory that holds both config and data
+// when an environment override or portable mode is active, or "" otherwise
+func getBaseOverride() (string, error) {
+	if home := os.Getenv(homeEnvVar); home != "" {
+		return filepath.Abs(home)
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		// Without the executable path portable mode can't be resolved
+		if isTruthy(os.Getenv(portableEnvVar)) {
+			return "", fmt.Errorf("failed to locate executable for portable mode: %w", err)
+		}
+		return "", nil
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	exeDir := filepath.Dir(exePath)
+
+	if isTruthy(os.Getenv(portableEnvVar)) {
+		return filepath.Join(exeDir, portableDirName), nil
+	}
+	if _, err := os.Stat(filepath.Join(exeDir, portableMarker)); err == nil {
+		return filepath.Join(exeDir, portableDirName), nil
+	}
+
+	return "", nil
+}
+
+// IsPortableMode reports whether config and data are kept next to the binary
+// or under the MARKDOWNS_HOME override instead of the user's directories
+func IsPortableMode() bool {
+	base, err := getBaseOverride()
+	return err == nil && base != ""
+}
+
+// GetConfigDir returns the directory holding config.json
+func GetConfigDir() (string, error) {
+	base, err := getBaseOverride()
+	if err != nil {
+		return "", err
+	}
+	if base != "" {
+		return base, nil
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appDirName), nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, appDirName), nil
+}
+
+// GetDataDir returns the directory holding history, indexes and other state
+func GetDataDir() (string, error) {
+	base, err := getBaseOverride()
+	if err != nil {
+		return "", err
+	}
+	if base != "" {
+		return filepath.Join(base, "data"), nil
+	}
+
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appDirName), nil
+	}
+
+	// Only Linux and the BSDs have a dedicated data location,
+	// everywhere else data lives next to the config
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		configDir, err := GetConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(configDir, "data"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".local", "share", appDirName), nil
+}
+
+// getLegacyConfigDir returns the pre-XDG ~/.markdowns directory
+func getLegacyConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, legacyDirName), nil
+}
+
+// migrateLegacyConfig moves ~/.markdowns into the XDG locations once per run.
+// config.json goes to the config directory and everything else to the data directory.
+func migrateLegacyConfig() {
+	migrateOnce.Do(func() {
+		// Overrides and portable mode never read from the legacy location
+		if IsPortableMode() {
+			return
+		}
+
+		legacyDir, err := getLegacyConfigDir()
+		if err != nil {
+			return
+		}
+		if _, err := os.Stat(legacyDir); err != nil {
+			return
+		}
+
+		configDir, err := GetConfigDir()
+		if err != nil {
+			fmt.Printf("Warning: Could not migrate legacy config: %v\n", err)
+			return
+		}
+		dataDir, err := GetDataDir()
+		if err != nil {
+			fmt.Printf("Warning: Could not migrate legacy config: %v\n", err)
+			return
+		}
+
+		// Never overwrite a config that already exists in the new location
+		if _, err := os.Stat(filepath.Join(configDir, "config.json")); err == nil {
+			return
+		}
+
+		entries, err := os.ReadDir(legacyDir)
+		if err != nil {
+			fmt.Printf("Warning: Could not read legacy config directory: %v\n", err)
+			return
+		}
+
+		for _, entry := range entries {
+			targetDir := dataDir
+			if entry.Name() == "config.json" {
+				targetDir = configDir
+			}
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				fmt.Printf("Warning: Could not create %s: %v\n", targetDir, err)
+				return
+			}
+
+			src := filepath.Join(legacyDir, entry.Name())
+			dst := filepath.Join(targetDir, entry.Name())
+			if _, err := os.Stat(dst); err == nil {
+				continue
+			}
+			if err := os.Rename(src, dst); err != nil {
+				fmt.Printf("Warning: Could not migrate %s: %v\n", src, err)
+			}
+		}
+
+		// Remove the legacy directory only once everything moved out
+		if err := os.Remove(legacyDir); err != nil {
+			fmt.Printf("Warning: Could not remove legacy config directory %s: %v\n", legacyDir, err)
+		}
+	})
+}
+
+// isTruthy reports whether an environment value means "enabled"
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
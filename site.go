@@ -0,0 +1,702 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+)
+
+// SiteOptions controls static site generation
+type SiteOptions struct {
+	SourceDir   string `json:"sourceDir"`   // defaults to the workspace root
+	OutputDir   string `json:"outputDir"`   // defaults to "_site" inside the source folder
+	Title       string `json:"title"`       // defaults to the workspace name
+	Description string `json:"description"` // used by the RSS feed
+	BaseURL     string `json:"baseUrl"`     // absolute site URL for the sitemap and feed
+	Theme       string `json:"theme"`       // "light", "dark" or a path to a CSS file
+}
+
+// SiteResult summarises a generated site
+type SiteResult struct {
+	OutputDir string   `json:"outputDir"`
+	Pages     int      `json:"pages"`
+	Assets    int      `json:"assets"`
+	Skipped   []string `json:"skipped"` // notes not published, relative to the source folder
+}
+
+// sitePage is a published note
+type sitePage struct {
+	SourcePath string
+	URL        string // site-relative, slash separated, e.g. "guides/setup.html"
+	Doc        *markdownDocument
+	Title      string
+	Date       time.Time
+	ModTime    time.Time
+}
+
+// siteNavNode is a folder or page in the navigation tree
+type siteNavNode struct {
+	Name     string
+	URL      string
+	IsDir    bool
+	Children []*siteNavNode
+}
+
+// siteSearchEntry is one page in the search index
+type siteSearchEntry struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Headings []string `json:"headings"`
+	Text     string   `json:"text"`
+}
+
+// siteGenerator holds the state of one site build
+type siteGenerator struct {
+	options   SiteOptions
+	root      string
+	outputDir string
+	pages     map[string]*sitePage // by absolute source path
+	ordered   []*sitePage
+	assets    map[string]string // absolute source path -> site URL
+	ignore    *ignoreRules
+	nav       *siteNavNode
+	css       template.CSS
+	md        goldmark.Markdown
+}
+
+const maxSearchTextLength = 5000
+
+var sitePageTemplate = template.Must(template.New("site").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - {{.SiteTitle}}</title>
+<style>
+{{.CSS}}
+body { max-width: none; margin: 0; padding: 0; display: flex; min-height: 100vh; }
+nav.site-nav { flex: 0 0 260px; padding: 1.5rem 1rem; border-right: 1px solid rgba(127,127,127,0.25); font-size: 0.9em; overflow-y: auto; }
+nav.site-nav ul { list-style: none; margin: 0; padding-left: 1em; }
+nav.site-nav > ul { padding-left: 0; }
+nav.site-nav .active > a { font-weight: 600; }
+nav.site-nav .folder { display: block; margin-top: 0.5em; font-weight: 600; opacity: 0.8; }
+nav.site-nav input { width: 100%; box-sizing: border-box; margin: 0.75em 0; padding: 0.3em; }
+main { flex: 1; max-width: 820px; padding: 0 2rem 3rem; }
+#search-results li { margin: 0.25em 0; }
+</style>
+</head>
+<body>
+<nav class="site-nav">
+<a href="{{.RootPrefix}}index.html"><strong>{{.SiteTitle}}</strong></a>
+<input id="search" type="search" placeholder="Search">
+<ul id="search-results"></ul>
+{{.Nav}}
+</nav>
+<main>
+<article class="markdown-body">
+{{.Body}}
+</article>
+</main>
+<script>
+(function () {
+  var input = document.getElementById('search');
+  var results = document.getElementById('search-results');
+  var index = null;
+  input.addEventListener('input', function () {
+    var query = input.value.trim().toLowerCase();
+    results.innerHTML = '';
+    if (!query) return;
+    var run = function () {
+      index.filter(function (page) {
+        return (page.title + ' ' + page.headings.join(' ') + ' ' + page.text).toLowerCase().indexOf(query) !== -1;
+      }).slice(0, 20).forEach(function (page) {
+        var li = document.createElement('li');
+        var a = document.createElement('a');
+        a.href = '{{.RootPrefix}}' + page.url;
+        a.textContent = page.title;
+        li.appendChild(a);
+        results.appendChild(li);
+      });
+    };
+    if (index) return run();
+    fetch('{{.RootPrefix}}search-index.json').then(function (r) { return r.json(); }).then(function (data) { index = data; run(); });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// sitePageData is the data for sitePageTemplate
+type sitePageData struct {
+	Title      string
+	SiteTitle  string
+	CSS        template.CSS
+	Nav        template.HTML
+	Body       template.HTML
+	RootPrefix string
+}
+
+// GenerateSite publishes the notes in the workspace as a static HTML site
+func (a *App) GenerateSite(options SiteOptions) (SiteResult, error) {
+	root := options.SourceDir
+	if root == "" {
+		root = a.workspaceRoot()
+	}
+	if root == "" {
+		return SiteResult{}, fmt.Errorf("no workspace folder to publish")
+	}
+	root = filepath.Clean(root)
+
+	if options.Title == "" {
+		options.Title = a.workspaceName
+		if options.Title == "" {
+			options.Title = filepath.Base(root)
+		}
+	}
+
+	return generateSite(root, options)
+}
+
+// generateSite builds the site for the notes under root
+func generateSite(root string, options SiteOptions) (SiteResult, error) {
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(root, "_site")
+	}
+	outputDir = filepath.Clean(outputDir)
+
+	css, err := loadThemeCSS(options.Theme)
+	if err != nil {
+		return SiteResult{}, err
+	}
+
+	g := &siteGenerator{
+		options:   options,
+		root:      root,
+		outputDir: outputDir,
+		pages:     map[string]*sitePage{},
+		assets:    map[string]string{},
+		ignore:    loadIgnoreRules(root),
+		css:       template.CSS(css),
+		md:        newMarkdown(defaultCodeStyle(options.Theme)),
+	}
+
+	result := SiteResult{OutputDir: outputDir, Skipped: []string{}}
+
+	skipped, err := g.collectPages()
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = skipped
+
+	g.buildNav()
+
+	for _, page := range g.ordered {
+		if err := g.writePage(page); err != nil {
+			return result, err
+		}
+	}
+	if err := g.writeFolderIndexes(g.nav); err != nil {
+		return result, err
+	}
+	if err := g.writeSearchIndex(); err != nil {
+		return result, err
+	}
+	if err := g.writeSitemap(); err != nil {
+		return result, err
+	}
+	if err := g.writeFeed(); err != nil {
+		return result, err
+	}
+
+	result.Pages = len(g.ordered)
+	result.Assets = len(g.assets)
+	return result, nil
+}
+
+// collectPages parses every published note and assigns it a URL.
+// It returns the notes skipped because of "publish: false".
+func (g *siteGenerator) collectPages() ([]string, error) {
+	skipped := []string{}
+
+	err := walkNotes(g.root, []string{g.outputDir}, func(p string, rel string, d fs.DirEntry) error {
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+
+		doc, err := parseMarkdownFile(g.md, p)
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(doc.FrontMatter["publish"], "false") {
+			skipped = append(skipped, rel)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to get file info for %s: %w", p, err)
+		}
+
+		page := &sitePage{
+			SourcePath: p,
+			URL:        sitePageURL(rel),
+			Doc:        doc,
+			Title:      doc.title(),
+			ModTime:    info.ModTime(),
+		}
+		if date, ok := parseNoteDate(doc.FrontMatter["date"]); ok {
+			page.Date = date
+		}
+
+		g.pages[p] = page
+		g.ordered = append(g.ordered, page)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", g.root, err)
+	}
+
+	// README.md stands in for index.md when a folder has no index
+	urls := map[string]bool{}
+	for _, page := range g.ordered {
+		urls[page.URL] = true
+	}
+	for _, page := range g.ordered {
+		if strings.EqualFold(path.Base(page.URL), "readme.html") {
+			index := path.Join(path.Dir(page.URL), "index.html")
+			if !urls[index] {
+				urls[index] = true
+				page.URL = index
+			}
+		}
+	}
+
+	return skipped, nil
+}
+
+// sitePageURL maps a note path relative to the root to its page URL
+func sitePageURL(rel string) string {
+	rel = filepath.ToSlash(rel)
+	return strings.TrimSuffix(rel, path.Ext(rel)) + ".html"
+}
+
+// buildNav builds the navigation tree from the folder structure of the pages
+func (g *siteGenerator) buildNav() {
+	g.nav = &siteNavNode{Name: g.options.Title, URL: "index.html", IsDir: true}
+
+	for _, page := range g.ordered {
+		node := g.nav
+		dir := path.Dir(page.URL)
+		if dir != "." {
+			for i, part := range strings.Split(dir, "/") {
+				node = node.childDir(part, strings.Join(strings.Split(dir, "/")[:i+1], "/"))
+			}
+		}
+		if path.Base(page.URL) == "index.html" {
+			// A folder index is reached through the folder itself
+			continue
+		}
+		node.Children = append(node.Children, &siteNavNode{Name: page.Title, URL: page.URL})
+	}
+
+	g.nav.sort()
+}
+
+// childDir returns the folder child called name, creating it if needed
+func (n *siteNavNode) childDir(name string, dirURL string) *siteNavNode {
+	for _, child := range n.Children {
+		if child.IsDir && child.Name == name {
+			return child
+		}
+	}
+	child := &siteNavNode{Name: name, URL: dirURL + "/index.html", IsDir: true}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// sort orders folders before pages, each alphabetically
+func (n *siteNavNode) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		if n.Children[i].IsDir != n.Children[j].IsDir {
+			return n.Children[i].IsDir
+		}
+		return strings.ToLower(n.Children[i].Name) < strings.ToLower(n.Children[j].Name)
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+// navHTML renders the navigation tree with links relative to currentURL
+func (g *siteGenerator) navHTML(currentURL string) template.HTML {
+	var buf bytes.Buffer
+	var render func(nodes []*siteNavNode)
+	render = func(nodes []*siteNavNode) {
+		buf.WriteString("<ul>\n")
+		for _, node := range nodes {
+			class := ""
+			if node.URL == currentURL {
+				class = ` class="active"`
+			}
+			href := template.HTMLEscapeString(relativeURL(currentURL, node.URL))
+			name := template.HTMLEscapeString(node.Name)
+			if node.IsDir {
+				fmt.Fprintf(&buf, `<li%s><a class="folder" href="%s">%s</a>`, class, href, name)
+				render(node.Children)
+				buf.WriteString("</li>\n")
+			} else {
+				fmt.Fprintf(&buf, "<li%s><a href=\"%s\">%s</a></li>\n", class, href, name)
+			}
+		}
+		buf.WriteString("</ul>\n")
+	}
+	render(g.nav.Children)
+	return template.HTML(buf.String())
+}
+
+// relativeURL returns a link from the page at fromURL to the site URL toURL
+func relativeURL(fromURL string, toURL string) string {
+	fromDir := path.Dir(fromURL)
+	rel, err := filepath.Rel(filepath.FromSlash(fromDir), filepath.FromSlash(toURL))
+	if err != nil {
+		return toURL
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		if part != ".." {
+			parts[i] = url.PathEscape(part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// rootPrefix returns the relative path from the page at pageURL to the site root
+func rootPrefix(pageURL string) string {
+	depth := strings.Count(pageURL, "/")
+	return strings.Repeat("../", depth)
+}
+
+// writePage rewrites a note's links and images and writes its HTML page
+func (g *siteGenerator) writePage(page *sitePage) error {
+	g.rewriteLinks(page)
+
+	rewriteLocalImages(page.Doc, func(resolved string) (string, error) {
+		assetURL, err := g.copyAsset(resolved)
+		if err != nil {
+			return "", err
+		}
+		return relativeURL(page.URL, assetURL), nil
+	})
+
+	body, err := page.Doc.render(g.md)
+	if err != nil {
+		return err
+	}
+
+	return g.writeHTML(page.URL, page.Title, template.HTML(body))
+}
+
+// writeHTML writes a page with the site layout
+func (g *siteGenerator) writeHTML(pageURL string, title string, body template.HTML) error {
+	var buf bytes.Buffer
+	err := sitePageTemplate.Execute(&buf, sitePageData{
+		Title:      title,
+		SiteTitle:  g.options.Title,
+		CSS:        g.css,
+		Nav:        g.navHTML(pageURL),
+		Body:       body,
+		RootPrefix: rootPrefix(pageURL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render page %s: %w", pageURL, err)
+	}
+
+	return g.writeOutput(pageURL, buf.Bytes())
+}
+
+// writeOutput writes data to the site URL inside the output folder
+func (g *siteGenerator) writeOutput(siteURL string, data []byte) error {
+	target := filepath.Join(g.outputDir, filepath.FromSlash(siteURL))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", target, err)
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return nil
+}
+
+// rewriteLinks points links to published notes at their pages and copies
+// links to other local files into the site
+func (g *siteGenerator) rewriteLinks(page *sitePage) {
+	baseDir := filepath.Dir(page.SourcePath)
+
+	ast.Walk(page.Doc.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		dest := string(link.Destination)
+		if dest == "" || strings.HasPrefix(dest, "#") || isRemoteURL(dest) {
+			return ast.WalkContinue, nil
+		}
+
+		target, fragment, _ := strings.Cut(dest, "#")
+		if fragment != "" {
+			fragment = "#" + fragment
+		}
+
+		resolved, err := resolveImagePath(baseDir, target)
+		if err != nil {
+			fmt.Printf("Warning: Broken link %s in %s\n", dest, page.SourcePath)
+			return ast.WalkContinue, nil
+		}
+
+		if isMarkdownFile(resolved) {
+			if linked, ok := g.pages[resolved]; ok {
+				link.Destination = []byte(relativeURL(page.URL, linked.URL) + fragment)
+			} else {
+				fmt.Printf("Warning: %s links to unpublished note %s\n", page.SourcePath, target)
+			}
+			return ast.WalkContinue, nil
+		}
+
+		if info, err := os.Stat(resolved); err == nil && !info.IsDir() {
+			assetURL, err := g.copyAsset(resolved)
+			if err != nil {
+				fmt.Printf("Warning: Could not copy %s: %v\n", resolved, err)
+				return ast.WalkContinue, nil
+			}
+			link.Destination = []byte(relativeURL(page.URL, assetURL) + fragment)
+		}
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// copyAsset copies a referenced file into the site once and returns its site
+// URL, which keeps its path relative to the root. Files outside the root or
+// excluded by the ignore rules are refused so a link can't publish them.
+func (g *siteGenerator) copyAsset(source string) (string, error) {
+	if assetURL, ok := g.assets[source]; ok {
+		return assetURL, nil
+	}
+
+	rel, err := filepath.Rel(g.root, source)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s and is not published", source, g.root)
+	}
+	if g.ignore.excludes(rel, false) {
+		return "", fmt.Errorf("%s is excluded by the ignore rules and is not published", rel)
+	}
+	assetURL := filepath.ToSlash(rel)
+
+	if err := copyFile(source, filepath.Join(g.outputDir, filepath.FromSlash(assetURL))); err != nil {
+		return "", err
+	}
+
+	g.assets[source] = assetURL
+	return assetURL, nil
+}
+
+// writeFolderIndexes writes a listing page for every folder without its own index note
+func (g *siteGenerator) writeFolderIndexes(node *siteNavNode) error {
+	hasIndex := false
+	for _, page := range g.ordered {
+		if page.URL == node.URL {
+			hasIndex = true
+			break
+		}
+	}
+
+	if !hasIndex {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "<h1>%s</h1>\n<ul>\n", template.HTMLEscapeString(node.Name))
+		for _, child := range node.Children {
+			fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a></li>\n",
+				template.HTMLEscapeString(relativeURL(node.URL, child.URL)),
+				template.HTMLEscapeString(child.Name))
+		}
+		buf.WriteString("</ul>\n")
+
+		if err := g.writeHTML(node.URL, node.Name, template.HTML(buf.String())); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range node.Children {
+		if child.IsDir {
+			if err := g.writeFolderIndexes(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeSearchIndex writes search-index.json with the title, headings and text of every page
+func (g *siteGenerator) writeSearchIndex() error {
+	entries := make([]siteSearchEntry, 0, len(g.ordered))
+	for _, page := range g.ordered {
+		entry := siteSearchEntry{URL: page.URL, Title: page.Title, Headings: []string{}}
+
+		var text strings.Builder
+		ast.Walk(page.Doc.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			switch node := n.(type) {
+			case *ast.Heading:
+				entry.Headings = append(entry.Headings, nodeText(node, page.Doc.Source))
+				return ast.WalkSkipChildren, nil
+			case *ast.Paragraph, *ast.TextBlock:
+				text.WriteString(nodeText(node, page.Doc.Source))
+				text.WriteByte(' ')
+				return ast.WalkSkipChildren, nil
+			}
+			return ast.WalkContinue, nil
+		})
+
+		entry.Text = strings.TrimSpace(text.String())
+		if runes := []rune(entry.Text); len(runes) > maxSearchTextLength {
+			entry.Text = string(runes[:maxSearchTextLength])
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	return g.writeOutput("search-index.json", data)
+}
+
+// absoluteURL joins a site URL onto the base URL, or makes it root-relative without one
+func (g *siteGenerator) absoluteURL(siteURL string) string {
+	escaped := relativeURL("index.html", siteURL)
+	if g.options.BaseURL == "" {
+		return "/" + escaped
+	}
+	return strings.TrimSuffix(g.options.BaseURL, "/") + "/" + escaped
+}
+
+// writeSitemap writes sitemap.xml listing every page
+func (g *siteGenerator) writeSitemap() error {
+	type sitemapURL struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod,omitempty"`
+	}
+	type urlSet struct {
+		XMLName xml.Name     `xml:"urlset"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		URLs    []sitemapURL `xml:"url"`
+	}
+
+	set := urlSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, page := range g.ordered {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     g.absoluteURL(page.URL),
+			LastMod: page.ModTime.UTC().Format("2006-01-02"),
+		})
+	}
+
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sitemap: %w", err)
+	}
+	return g.writeOutput("sitemap.xml", append([]byte(xml.Header), data...))
+}
+
+// writeFeed writes feed.xml, an RSS 2.0 feed of the notes with a "date" in their front matter
+func (g *siteGenerator) writeFeed() error {
+	type rssItem struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		GUID        string `xml:"guid"`
+		PubDate     string `xml:"pubDate"`
+		Description string `xml:"description,omitempty"`
+	}
+	type rssChannel struct {
+		Title       string    `xml:"title"`
+		Link        string    `xml:"link"`
+		Description string    `xml:"description"`
+		Items       []rssItem `xml:"item"`
+	}
+	type rss struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+
+	var dated []*sitePage
+	for _, page := range g.ordered {
+		if !page.Date.IsZero() {
+			dated = append(dated, page)
+		}
+	}
+	sort.SliceStable(dated, func(i, j int) bool {
+		return dated[i].Date.After(dated[j].Date)
+	})
+
+	description := g.options.Description
+	if description == "" {
+		description = g.options.Title
+	}
+	feed := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       g.options.Title,
+			Link:        g.absoluteURL("index.html"),
+			Description: description,
+		},
+	}
+	for _, page := range dated {
+		link := g.absoluteURL(page.URL)
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       page.Title,
+			Link:        link,
+			GUID:        link,
+			PubDate:     page.Date.Format(time.RFC1123Z),
+			Description: page.Doc.FrontMatter["description"],
+		})
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	return g.writeOutput("feed.xml", append([]byte(xml.Header), data...))
+}
+
+// parseNoteDate parses a front matter date in the common layouts
+func parseNoteDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// notesChangedEvent carries the paths of notes rewritten outside the editor
+const notesChangedEvent = "notes:changed"
+
+// Tab represents an open document
+type Tab struct {
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Dirty       bool   `json:"dirty"`
+	ContentHash string `json:"contentHash"` // hash of the content on disk
+	Active      bool   `json:"active"`
+}
+
+// OpenTab opens a file in a tab, or activates its tab if already open
+func (a *App) OpenTab(path string) ([]Tab, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path %s is a directory, not a file", path)
+	}
+
+	a.tabsMu.Lock()
+	if a.findTab(path) < 0 {
+		tab := &Tab{
+			Path: path,
+			Name: filepath.Base(path),
+		}
+		if content, err := os.ReadFile(path); err == nil {
+			tab.ContentHash = hashContent(string(content))
+		}
+		a.tabs = append(a.tabs, tab)
+	}
+	a.activateTab(path)
+	tabs := a.copyTabs()
+	a.tabsMu.Unlock()
+
+	if err := a.saveTabs(); err != nil {
+		fmt.Printf("Warning: Could not save tabs: %v\n", err)
+	}
+
+	// Also add to recent files
+	a.AddRecentFile(path)
+
+	return tabs, nil
+}
+
+// CloseTab closes the tab for path. If it was active the next tab, or the
+// previous one when closing the last tab, becomes active.
+func (a *App) CloseTab(path string) ([]Tab, error) {
+	a.tabsMu.Lock()
+	index := a.findTab(path)
+	if index < 0 {
+		a.tabsMu.Unlock()
+		return nil, fmt.Errorf("no open tab for %s", path)
+	}
+	a.removeTab(index)
+	tabs := a.copyTabs()
+	a.tabsMu.Unlock()
+
+	if err := a.saveTabs(); err != nil {
+		fmt.Printf("Warning: Could not save tabs: %v\n", err)
+	}
+
+	return tabs, nil
+}
+
+// ListTabs returns the open tabs in order
+func (a *App) ListTabs() []Tab {
+	a.tabsMu.Lock()
+	defer a.tabsMu.Unlock()
+
+	return a.copyTabs()
+}
+
+// SetActiveTab makes the tab for path the current file
+func (a *App) SetActiveTab(path string) ([]Tab, error) {
+	a.tabsMu.Lock()
+	if a.findTab(path) < 0 {
+		a.tabsMu.Unlock()
+		return nil, fmt.Errorf("no open tab for %s", path)
+	}
+	a.activateTab(path)
+	tabs := a.copyTabs()
+	a.tabsMu.Unlock()
+
+	if err := a.saveTabs(); err != nil {
+		fmt.Printf("Warning: Could not save tabs: %v\n", err)
+	}
+
+	return tabs, nil
+}
+
+// UpdateTabContent marks the tab dirty when content differs from what is on disk
+func (a *App) UpdateTabContent(path string, content string) (Tab, error) {
+	a.tabsMu.Lock()
+	defer a.tabsMu.Unlock()
+
+	index := a.findTab(path)
+	if index < 0 {
+		return Tab{}, fmt.Errorf("no open tab for %s", path)
+	}
+
+	tab := a.tabs[index]
+	tab.Dirty = hashContent(content) != tab.ContentHash
+
+	return *tab, nil
+}
+
+// findTab returns the index of the tab for path, or -1. Callers hold tabsMu.
+func (a *App) findTab(path string) int {
+	for i, tab := range a.tabs {
+		if tab.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// activateTab marks the tab for path active and makes it the current file.
+// Callers hold tabsMu.
+func (a *App) activateTab(path string) {
+	for _, tab := range a.tabs {
+		tab.Active = tab.Path == path
+	}
+
+	if a.currentFile != path {
+		a.currentFile = path
+		a.currentFileContent = ""
+	}
+}
+
+// removeTab removes the tab at index, activating a neighbour if it was active.
+// Callers hold tabsMu.
+func (a *App) removeTab(index int) {
+	wasActive := a.tabs[index].Active
+	a.tabs = append(a.tabs[:index], a.tabs[index+1:]...)
+
+	if !wasActive {
+		return
+	}
+
+	if len(a.tabs) == 0 {
+		a.currentFile = ""
+		a.currentFileContent = ""
+		return
+	}
+
+	if index >= len(a.tabs) {
+		index = len(a.tabs) - 1
+	}
+	a.activateTab(a.tabs[index].Path)
+}
+
+// copyTabs returns a snapshot of the open tabs. Callers hold tabsMu.
+func (a *App) copyTabs() []Tab {
+	tabs := make([]Tab, 0, len(a.tabs))
+	for _, tab := range a.tabs {
+		tabs = append(tabs, *tab)
+	}
+	return tabs
+}
+
+// markTabSaved records that content was written to path
+func (a *App) markTabSaved(path string, content string) {
+	a.tabsMu.Lock()
+	defer a.tabsMu.Unlock()
+
+	if index := a.findTab(path); index >= 0 {
+		a.tabs[index].ContentHash = hashContent(content)
+		a.tabs[index].Dirty = false
+	}
+}
+
+// hasUnsavedEdits reports whether path is open in a tab with unsaved edits.
+// Writers other than the editor leave such notes alone so the edits are not lost.
+func (a *App) hasUnsavedEdits(path string) bool {
+	a.tabsMu.Lock()
+	defer a.tabsMu.Unlock()
+
+	index := a.findTab(path)
+	return index >= 0 && a.tabs[index].Dirty
+}
+
+// reloadTabs updates the current file and the tabs of paths, just rewritten
+// on disk, to match their new content and tells the frontend to reload them
+func (a *App) reloadTabs(paths []string) {
+	var open []string
+	for _, path := range paths {
+		a.tabsMu.Lock()
+		isOpen := a.findTab(path) >= 0 || a.currentFile == path
+		a.tabsMu.Unlock()
+		if !isOpen {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if a.currentFile == path {
+			a.currentFileContent = string(content)
+		}
+		a.markTabSaved(path, string(content))
+		open = append(open, path)
+	}
+	a.notifyNotesChanged(open...)
+}
+
+// notifyNotesChanged tells the frontend that notes were rewritten outside the
+// editor so open tabs can reload them
+func (a *App) notifyNotesChanged(paths ...string) {
+	if len(paths) > 0 {
+		a.emitEvent(notesChangedEvent, paths)
+	}
+}
+
+// closeTabsUnder closes the tab for path and, for directories, every tab inside it
+func (a *App) closeTabsUnder(path string) {
+	a.tabsMu.Lock()
+	changed := false
+	for i := len(a.tabs) - 1; i >= 0; i-- {
+		if isSameOrChildPath(a.tabs[i].Path, path) {
+			a.removeTab(i)
+			changed = true
+		}
+	}
+	a.tabsMu.Unlock()
+
+	if changed {
+		if err := a.saveTabs(); err != nil {
+			fmt.Printf("Warning: Could not save tabs: %v\n", err)
+		}
+	}
+}
+
+// renameTabsUnder updates tabs after oldPath was renamed to newPath
+func (a *App) renameTabsUnder(oldPath string, newPath string) {
+	a.tabsMu.Lock()
+	changed := false
+	for _, tab := range a.tabs {
+		if isSameOrChildPath(tab.Path, oldPath) {
+			tab.Path = newPath + strings.TrimPrefix(tab.Path, oldPath)
+			tab.Name = filepath.Base(tab.Path)
+			changed = true
+		}
+	}
+	if isSameOrChildPath(a.currentFile, oldPath) {
+		a.currentFile = newPath + strings.TrimPrefix(a.currentFile, oldPath)
+		changed = true
+	}
+	a.tabsMu.Unlock()
+
+	if changed {
+		if err := a.saveTabs(); err != nil {
+			fmt.Printf("Warning: Could not save tabs: %v\n", err)
+		}
+	}
+}
+
+// restoreTabs reopens the tabs saved in config, skipping files that no longer exist
+func (a *App) restoreTabs(config *Config) {
+	a.tabsMu.Lock()
+	defer a.tabsMu.Unlock()
+
+	a.tabs = nil
+	paths := config.OpenTabs
+	if config.LastOpenedFile != "" && !slices.Contains(paths, config.LastOpenedFile) {
+		paths = append(paths, config.LastOpenedFile)
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil || a.findTab(path) >= 0 {
+			continue
+		}
+		a.tabs = append(a.tabs, &Tab{
+			Path:        path,
+			Name:        filepath.Base(path),
+			ContentHash: hashContent(string(content)),
+		})
+	}
+
+	if a.findTab(config.LastOpenedFile) >= 0 {
+		a.activateTab(config.LastOpenedFile)
+	} else if len(a.tabs) > 0 {
+		a.activateTab(a.tabs[0].Path)
+	} else {
+		a.currentFile = ""
+		a.currentFileContent = ""
+	}
+}
+
+// saveTabs persists the open tabs and the active one to config. Nothing is
+// written when the config can't be loaded, so a bad read never replaces the
+// user's settings with defaults.
+func (a *App) saveTabs() error {
+	a.tabsMu.Lock()
+	paths := make([]string, 0, len(a.tabs))
+	for _, tab := range a.tabs {
+		paths = append(paths, tab.Path)
+	}
+	activePath := a.currentFile
+	a.tabsMu.Unlock()
+
+	return updateConfig(func(config *Config) error {
+		config.OpenTabs = paths
+		config.LastOpenedFile = activePath
+		return nil
+	})
+}
+
+// isSameOrChildPath reports whether path equals parent or lies inside it
+func isSameOrChildPath(path string, parent string) bool {
+	if path == parent {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(parent, string(filepath.Separator))+string(filepath.Separator))
+}
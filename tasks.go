@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Task is a GFM checklist item in a note
+type Task struct {
+	Path      string   `json:"path"`
+	Line      int      `json:"line"` // 1-based
+	Text      string   `json:"text"`
+	Done      bool     `json:"done"`
+	Heading   string   `json:"heading"`   // the heading the task sits under, "" before the first one
+	Due       string   `json:"due"`       // from "due:YYYY-MM-DD", "" when unset
+	Assignees []string `json:"assignees"` // from "@person"
+	Priority  string   `json:"priority"`  // from "!high", "!1" and so on, "" when unset
+}
+
+// TaskFilter narrows ListTasks. Empty fields match everything.
+type TaskFilter struct {
+	Folder    string `json:"folder"`    // only tasks under this folder or in this note, absolute or relative to the workspace root
+	Status    string `json:"status"`    // "open" or "done"
+	Assignee  string `json:"assignee"`  // without the "@"
+	Priority  string `json:"priority"`  // without the "!"
+	DueBefore string `json:"dueBefore"` // YYYY-MM-DD, inclusive; tasks without a due date don't match
+	Query     string `json:"query"`     // case-insensitive text search
+}
+
+var (
+	// taskPattern matches a checklist item: indent, list marker, box and text
+	taskPattern     = regexp.MustCompile(`^(\s*(?:>\s*)*(?:[-*+]|\d+[.)])\s+\[)([ xX])(\]\s+(\S.*))$`)
+	taskDuePattern  = regexp.MustCompile(`(?:^|\s)due:(\d{4}-\d{2}-\d{2})\b`)
+	taskUserPattern = regexp.MustCompile(`(?:^|\s)@([\pL\pN_][\pL\pN_.-]*[\pL\pN_]|[\pL\pN_])`)
+	taskPrioPattern = regexp.MustCompile(`(?:^|\s)!([\pL\pN_]+)`)
+)
+
+// taskIndex caches the tasks of each note until the note changes on disk
+type taskIndex struct {
+	mu    sync.Mutex
+	files map[string]taskIndexEntry
+}
+
+// taskIndexEntry is the tasks of one note as of its modification time and size
+type taskIndexEntry struct {
+	modTime time.Time
+	size    int64
+	tasks   []Task
+}
+
+func newTaskIndex() *taskIndex {
+	return &taskIndex{files: map[string]taskIndexEntry{}}
+}
+
+// tasks returns the tasks of a note, parsing it again only if it changed
+func (idx *taskIndex) tasks(path string, info fs.FileInfo) ([]Task, error) {
+	idx.mu.Lock()
+	entry, ok := idx.files[path]
+	idx.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.tasks, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	tasks := parseTasks(path, string(content))
+
+	idx.store(path, info, tasks)
+	return tasks, nil
+}
+
+// store records the tasks of a note as of info
+func (idx *taskIndex) store(path string, info fs.FileInfo, tasks []Task) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.files[path] = taskIndexEntry{modTime: info.ModTime(), size: info.Size(), tasks: tasks}
+}
+
+// prune forgets the notes not in keep, such as ones deleted since they were cached
+func (idx *taskIndex) prune(keep map[string]bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for path := range idx.files {
+		if !keep[path] {
+			delete(idx.files, path)
+		}
+	}
+}
+
+// ListTasks returns the checklist items of every note in the workspace that
+// matches filter, ordered by note and line
+func (a *App) ListTasks(filter TaskFilter) ([]Task, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return nil, fmt.Errorf("no workspace is open")
+	}
+
+	folder := ""
+	if filter.Folder != "" {
+		folder = resolveInWorkspace(root, filter.Folder)
+	}
+
+	tasks := []Task{}
+	seen := map[string]bool{}
+	err := walkNotes(root, nil, func(path string, rel string, d fs.DirEntry) error {
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+		seen[path] = true
+		if folder != "" && !isSameOrChildPath(path, folder) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to get file info for %s: %w", path, err)
+		}
+		fileTasks, err := a.tasks.tasks(path, info)
+		if err != nil {
+			return err
+		}
+		for _, task := range fileTasks {
+			if filter.matches(task) {
+				tasks = append(tasks, task)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", root, err)
+	}
+	a.tasks.prune(seen)
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Path != tasks[j].Path {
+			return tasks[i].Path < tasks[j].Path
+		}
+		return tasks[i].Line < tasks[j].Line
+	})
+	return tasks, nil
+}
+
+// matches reports whether task passes the filter
+func (f TaskFilter) matches(task Task) bool {
+	switch f.Status {
+	case "open":
+		if task.Done {
+			return false
+		}
+	case "done":
+		if !task.Done {
+			return false
+		}
+	}
+	if f.Assignee != "" {
+		found := false
+		for _, assignee := range task.Assignees {
+			if strings.EqualFold(assignee, strings.TrimPrefix(f.Assignee, "@")) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Priority != "" && !strings.EqualFold(task.Priority, strings.TrimPrefix(f.Priority, "!")) {
+		return false
+	}
+	// Dates in YYYY-MM-DD form compare correctly as strings
+	if f.DueBefore != "" && (task.Due == "" || task.Due > f.DueBefore) {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(task.Text), strings.ToLower(f.Query)) {
+		return false
+	}
+	return true
+}
+
+// ToggleTask flips the checkbox of the task on a 1-based line of a note and
+// saves it. Nothing else in the file changes. A note with unsaved edits in a
+// tab is refused, since saving it from disk would drop those edits.
+func (a *App) ToggleTask(path string, line int) (Task, error) {
+	if path == "" {
+		return Task{}, fmt.Errorf("file path cannot be empty")
+	}
+	if a.hasUnsavedEdits(path) {
+		return Task{}, fmt.Errorf("%s has unsaved changes, save it before toggling its tasks", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	content := string(data)
+
+	lines := markdownBodyLines(content)
+	if line < 1 || line > len(lines) || lines[line-1].frontMatter || lines[line-1].code {
+		return Task{}, fmt.Errorf("line %d of %s is not a task", line, path)
+	}
+	match := taskPattern.FindStringSubmatchIndex(lines[line-1].text)
+	if match == nil {
+		return Task{}, fmt.Errorf("line %d of %s is not a task", line, path)
+	}
+
+	// Offset of the box character in the file
+	start := 0
+	for i := 0; i < line-1; i++ {
+		start += strings.IndexByte(content[start:], '\n') + 1
+	}
+	offset := start + match[4]
+	mark := "x"
+	if content[offset] != ' ' {
+		mark = " "
+	}
+	updated := content[:offset] + mark + content[offset+1:]
+
+	if err := a.SaveFile(path, updated); err != nil {
+		return Task{}, err
+	}
+	a.notifyNotesChanged(path)
+
+	// Toggling keeps the size, and the modification time may not move on a
+	// coarse clock, so the cache can't be trusted to notice the change
+	tasks := parseTasks(path, updated)
+	if info, err := os.Stat(path); err == nil {
+		a.tasks.store(path, info, tasks)
+	}
+
+	for _, task := range tasks {
+		if task.Line == line {
+			return task, nil
+		}
+	}
+	return Task{}, fmt.Errorf("line %d of %s is not a task", line, path)
+}
+
+// parseTasks returns the checklist items in a note, skipping code blocks and front matter
+func parseTasks(path string, content string) []Task {
+	headings := outlineHeadings(content)
+
+	var tasks []Task
+	next := 0
+	heading := ""
+	for i, line := range markdownBodyLines(content) {
+		lineNumber := i + 1
+		for next < len(headings) && headings[next].Line <= lineNumber {
+			heading = headings[next].Text
+			next++
+		}
+		if line.frontMatter || line.code {
+			continue
+		}
+
+		match := taskPattern.FindStringSubmatch(line.text)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[4])
+
+		task := Task{
+			Path:      path,
+			Line:      lineNumber,
+			Text:      text,
+			Done:      match[2] != " ",
+			Heading:   heading,
+			Assignees: []string{},
+		}
+		if due := taskDuePattern.FindStringSubmatch(text); due != nil {
+			task.Due = due[1]
+		}
+		for _, user := range taskUserPattern.FindAllStringSubmatch(text, -1) {
+			task.Assignees = append(task.Assignees, user[1])
+		}
+		if priority := taskPrioPattern.FindStringSubmatch(text); priority != nil {
+			task.Priority = priority[1]
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTasks(t *testing.T) {
+	content := "---\n" +
+		"- [ ] front matter\n" +
+		"---\n" +
+		"- [ ] plain\n" +
+		"## Work\n" +
+		"* [x] ship due:2024-03-01 @ann @bob.k !high\n" +
+		"```\n" +
+		"- [ ] in code\n" +
+		"```\n" +
+		"  1. [X] numbered !2 email@example.com\n" +
+		"> - [ ] quoted due:2024-13 !\n" +
+		"- [] not a task\n"
+
+	want := []Task{
+		{Line: 4, Text: "plain", Assignees: []string{}},
+		{Line: 6, Text: "ship due:2024-03-01 @ann @bob.k !high", Done: true, Heading: "Work",
+			Due: "2024-03-01", Assignees: []string{"ann", "bob.k"}, Priority: "high"},
+		{Line: 10, Text: "numbered !2 email@example.com", Done: true, Heading: "Work",
+			Assignees: []string{}, Priority: "2"},
+		{Line: 11, Text: "quoted due:2024-13 !", Heading: "Work", Assignees: []string{}},
+	}
+	for i := range want {
+		want[i].Path = "note.md"
+	}
+
+	if got := parseTasks("note.md", content); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTasks() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestToggleTaskUpdatesTaskList(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	note := filepath.Join(root, "note.md")
+	gone := filepath.Join(root, "gone.md")
+	writeTestFile(t, note, "- [ ] one\n")
+	writeTestFile(t, gone, "- [ ] two\n")
+
+	app := NewApp()
+	app.workspacePath = root
+	if tasks, err := app.ListTasks(TaskFilter{}); err != nil || len(tasks) != 2 {
+		t.Fatalf("ListTasks() = %+v, %v, want two tasks", tasks, err)
+	}
+
+	// The toggle keeps the note's size, so only the cache update makes it show
+	info, _ := os.Stat(note)
+	if _, err := app.ToggleTask(note, 1); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(note, info.ModTime(), info.ModTime())
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := app.ListTasks(TaskFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || !tasks[0].Done {
+		t.Errorf("ListTasks() after toggling = %+v, want one done task", tasks)
+	}
+	if _, cached := app.tasks.files[gone]; cached {
+		t.Error("the deleted note is still cached")
+	}
+}
+
+func TestToggleTaskRefusesUnsavedTab(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	note := filepath.Join(t.TempDir(), "note.md")
+	writeTestFile(t, note, "- [ ] one\n")
+
+	app := NewApp()
+	if _, err := app.OpenTab(note); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTabContent(note, "- [ ] one, unsaved\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.ToggleTask(note, 1); err == nil {
+		t.Error("ToggleTask saved a note with unsaved edits")
+	}
+	if data, _ := os.ReadFile(note); string(data) != "- [ ] one\n" {
+		t.Errorf("note reads %q after the refused toggle", data)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+)
+
+// templatesDirName is the workspace folder note templates are kept in
+const templatesDirName = "templates"
+
+// folderTemplateFileName is a file in a folder naming the template new notes
+// there start from. It applies to subfolders too unless they have their own.
+const folderTemplateFileName = ".markdownstemplate"
+
+// NoteTemplate is a template in the templates folder
+type NoteTemplate struct {
+	Name    string   `json:"name"` // path inside the templates folder without the extension
+	Path    string   `json:"path"`
+	Prompts []string `json:"prompts"` // labels of the {{prompt:...}} variables to ask the user for
+}
+
+// CreateFromTemplateResult is a note created from a template
+type CreateFromTemplateResult struct {
+	Path     string `json:"path"`
+	Template string `json:"template"` // "" when no template applied
+	// CursorOffset is where {{cursor}} was, in UTF-16 code units as the editor
+	// counts them, or -1 when the template has no cursor marker
+	CursorOffset int `json:"cursorOffset"`
+}
+
+// noteVariablePattern matches "{{name}}" and "{{name:argument}}" in note templates
+var noteVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z]+)\s*(?::([^}]*))?\}\}`)
+
+// cursorMarker stands in for {{cursor}} until its offset is known
+const cursorMarker = "\x00cursor\x00"
+
+// noteTemplateContext is what note template variables are filled from
+type noteTemplateContext struct {
+	Date  time.Time         // for {{date}}
+	Now   time.Time         // for {{time}}
+	Title string            // for {{title}}
+	Vars  map[string]string // for {{prompt:Label}} and any other {{name}}
+}
+
+// renderNoteTemplate fills in the variables of a note template:
+//
+//	{{date}} {{date:Go layout}}  the note's date, 2006-01-02 by default
+//	{{time}} {{time:Go layout}}  the current time, 15:04 by default
+//	{{title}}                    the note's name without its extension
+//	{{uuid}}                     a new random UUID
+//	{{cursor}}                   removed; its offset is returned, or -1
+//	{{prompt:Label}}             the value supplied for Label, "" if none
+//
+// Any other {{name}} is taken from Vars when present and left alone otherwise.
+func renderNoteTemplate(template string, ctx noteTemplateContext) (string, int) {
+	cursorUsed := false
+	rendered := noteVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		parts := noteVariablePattern.FindStringSubmatch(match)
+		name, argument := parts[1], strings.TrimSpace(parts[2])
+		switch strings.ToLower(name) {
+		case "date":
+			if argument == "" {
+				argument = "2006-01-02"
+			}
+			return ctx.Date.Format(argument)
+		case "time":
+			if argument == "" {
+				argument = "15:04"
+			}
+			return ctx.Now.Format(argument)
+		case "title":
+			return ctx.Title
+		case "uuid":
+			return uuid.NewString()
+		case "cursor":
+			// Only the first marker places the cursor
+			if cursorUsed {
+				return ""
+			}
+			cursorUsed = true
+			return cursorMarker
+		case "prompt":
+			return ctx.Vars[argument]
+		}
+		if value, ok := ctx.Vars[name]; ok && argument == "" {
+			return value
+		}
+		return match
+	})
+
+	before, after, found := strings.Cut(rendered, cursorMarker)
+	if !found {
+		return rendered, -1
+	}
+	return before + after, len(utf16.Encode([]rune(before)))
+}
+
+// templatePrompts returns the labels of a template's {{prompt:...}} variables in order of appearance
+func templatePrompts(template string) []string {
+	prompts := []string{}
+	seen := map[string]bool{}
+	for _, parts := range noteVariablePattern.FindAllStringSubmatch(template, -1) {
+		label := strings.TrimSpace(parts[2])
+		if strings.EqualFold(parts[1], "prompt") && label != "" && !seen[label] {
+			seen[label] = true
+			prompts = append(prompts, label)
+		}
+	}
+	return prompts
+}
+
+// ListTemplates returns the templates in the workspace templates folder
+func (a *App) ListTemplates() ([]NoteTemplate, error) {
+	root := a.workspaceRoot()
+	if root == "" {
+		return nil, fmt.Errorf("no workspace is open")
+	}
+
+	templates := []NoteTemplate{}
+	dir := filepath.Join(root, templatesDirName)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return templates, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isMarkdownFile(d.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, NoteTemplate{
+			Name:    strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel)),
+			Path:    path,
+			Prompts: templatePrompts(string(content)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates in %s: %w", dir, err)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// readNoteTemplate reads a template from the templates folder by name, with or
+// without its extension
+func readNoteTemplate(root string, name string) (string, error) {
+	rel, err := safeArchivePath(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid template name %q: %w", name, err)
+	}
+
+	base := filepath.Join(root, templatesDirName, filepath.FromSlash(rel))
+	candidates := []string{base}
+	if !isMarkdownFile(base) {
+		candidates = []string{base + ".md", base + ".markdown"}
+	}
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+	}
+	return "", fmt.Errorf("template %s not found in %s", name, templatesDirName)
+}
+
+// GetFolderTemplate returns the name of the template new notes in dir start
+// from, declared by the nearest folderTemplateFileName in dir or a parent
+// folder inside the workspace, or "" when there is none
+func (a *App) GetFolderTemplate(dir string) (string, error) {
+	root := a.workspaceRoot()
+	if root == "" || !isSameOrChildPath(dir, root) {
+		return "", nil
+	}
+
+	for current := filepath.Clean(dir); isSameOrChildPath(current, root); current = filepath.Dir(current) {
+		data, err := os.ReadFile(filepath.Join(current, folderTemplateFileName))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", folderTemplateFileName, err)
+		}
+		if current == root {
+			break
+		}
+	}
+	return "", nil
+}
+
+// SetFolderTemplate makes template the default for new notes in dir and its
+// subfolders. An empty template removes the folder's default.
+func (a *App) SetFolderTemplate(dir string, template string) error {
+	path := filepath.Join(dir, folderTemplateFileName)
+	if template == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+
+	root := a.workspaceRoot()
+	if root == "" {
+		return fmt.Errorf("no workspace is open")
+	}
+	if _, err := readNoteTemplate(root, template); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(template+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// CreateFileFromTemplate creates a note called name in the current directory
+// from a template in the templates folder. An empty template uses the
+// folder's default, if it has one. vars supplies the answers to the
+// template's prompts and any other variables it uses.
+func (a *App) CreateFileFromTemplate(name string, template string, vars map[string]string) (CreateFromTemplateResult, error) {
+	if name == "" {
+		return CreateFromTemplateResult{}, fmt.Errorf("file name cannot be empty")
+	}
+	return a.createNoteInDir(a.currentDir, name, template, vars)
+}
+
+// createNoteInDir creates a note in dir from template, or from the folder's
+// default template when template is "" and name is a markdown file, or empty
+// when there is neither. name is cleaned with safeRelativeName and may
+// include folders, which are created as needed.
+func (a *App) createNoteInDir(dir string, name string, template string, vars map[string]string) (CreateFromTemplateResult, error) {
+	result := CreateFromTemplateResult{CursorOffset: -1}
+
+	rel, err := safeRelativeName(name, fileNameSettings(), true, ".md")
+	if err != nil {
+		return result, err
+	}
+	dir = filepath.Join(dir, filepath.Dir(rel))
+	name = filepath.Base(rel)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	if template == "" && isMarkdownFile(name) {
+		folderTemplate, err := a.GetFolderTemplate(dir)
+		if err != nil {
+			return result, err
+		}
+		template = folderTemplate
+	}
+
+	content := ""
+	if template != "" {
+		root := a.workspaceRoot()
+		if root == "" {
+			return result, fmt.Errorf("no workspace is open to read templates from")
+		}
+		source, err := readNoteTemplate(root, template)
+		if err != nil {
+			return result, err
+		}
+		now := time.Now()
+		content, result.CursorOffset = renderNoteTemplate(source, noteTemplateContext{
+			Date:  now,
+			Now:   now,
+			Title: strings.TrimSuffix(name, filepath.Ext(name)),
+			Vars:  vars,
+		})
+		result.Template = template
+	}
+
+	path, err := createFileWithContent(dir, name, content)
+	if err != nil {
+		return result, err
+	}
+	result.Path = path
+	return result, nil
+}
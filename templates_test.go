@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderNoteTemplate(t *testing.T) {
+	ctx := noteTemplateContext{
+		Date:  time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Now:   time.Date(2024, 3, 5, 9, 7, 0, 0, time.UTC),
+		Title: "Plan",
+		Vars:  map[string]string{"Owner": "Ann", "project": "Kite"},
+	}
+	for _, tc := range []struct {
+		template string
+		want     string
+		cursor   int
+	}{
+		{"# {{title}}\n{{cursor}}", "# Plan\n", 7},
+		{"no cursor", "no cursor", -1},
+		{"{{cursor}}start", "start", 0},
+		{"{{date}} {{time}} {{date:Jan 2}} {{ time : 15h04 }}", "2024-03-05 09:07 Mar 5 09h07", -1},
+		{"{{prompt:Owner}}/{{project}}/{{prompt:Missing}}/{{unknown}}", "Ann/Kite//{{unknown}}", -1},
+		{"{{TITLE}} {{cursor}}and {{cursor}}again", "Plan and again", 5},
+		// Offsets count UTF-16 code units, as the editor does
+		{"é€{{cursor}}", "é€", 2},
+		{"😀 {{title}}{{cursor}}", "😀 Plan", 7},
+	} {
+		got, cursor := renderNoteTemplate(tc.template, ctx)
+		if got != tc.want || cursor != tc.cursor {
+			t.Errorf("renderNoteTemplate(%q) = %q, %d, want %q, %d", tc.template, got, cursor, tc.want, tc.cursor)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markers around a generated table of contents. "<!-- /toc -->" is accepted
+// as an end marker too, since other tools write it.
+const (
+	tocStartMarker = "<!-- toc -->"
+	tocEndMarker   = "<!-- tocstop -->"
+)
+
+// Anchor styles for table of contents links
+const (
+	anchorStyleGitHub = "github"
+	anchorStyleGitLab = "gitlab"
+	anchorStyleNone   = "none" // plain text entries without links
+)
+
+// TOCOptions controls how a table of contents is generated
+type TOCOptions struct {
+	MinLevel    int    `json:"minLevel"`    // shallowest heading level to include, 0 for 1
+	MaxLevel    int    `json:"maxLevel"`    // deepest heading level to include, 0 for 6
+	Numbered    bool   `json:"numbered"`    // numbered list instead of bullets
+	AnchorStyle string `json:"anchorStyle"` // "github" (default), "gitlab" or "none"
+}
+
+// TOCResult describes a table of contents update
+type TOCResult struct {
+	Path    string `json:"path"`
+	TOC     string `json:"toc"`
+	Changed bool   `json:"changed"`
+}
+
+// hyphenRunPattern matches the repeated hyphens GitLab collapses in anchors
+var hyphenRunPattern = regexp.MustCompile(`-{2,}`)
+
+// UpdateTOC regenerates the table of contents between the toc markers of a
+// note and saves it. A note without markers gets them after its title heading.
+// A note with unsaved edits in a tab is refused, since saving it from disk
+// would drop those edits.
+func (a *App) UpdateTOC(path string, options TOCOptions) (TOCResult, error) {
+	result := TOCResult{Path: path}
+	if path == "" {
+		return result, fmt.Errorf("file path cannot be empty")
+	}
+	if a.hasUnsavedEdits(path) {
+		return result, fmt.Errorf("%s has unsaved changes, save it before updating its table of contents", path)
+	}
+	if err := validateTOCOptions(options); err != nil {
+		return result, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	content := string(data)
+
+	updated, toc, _ := updateTOCContent(content, options, true)
+	result.TOC = toc
+	if updated == content {
+		return result, nil
+	}
+
+	if err := a.SaveFile(path, updated); err != nil {
+		return result, err
+	}
+	a.notifyNotesChanged(path)
+	result.Changed = true
+	return result, nil
+}
+
+// validateTOCOptions checks the level range and anchor style
+func validateTOCOptions(options TOCOptions) error {
+	if options.MinLevel < 0 || options.MinLevel > 6 || options.MaxLevel < 0 || options.MaxLevel > 6 {
+		return fmt.Errorf("heading levels must be between 1 and 6")
+	}
+	if options.MinLevel > 0 && options.MaxLevel > 0 && options.MinLevel > options.MaxLevel {
+		return fmt.Errorf("minimum heading level %d is deeper than the maximum %d", options.MinLevel, options.MaxLevel)
+	}
+	switch options.AnchorStyle {
+	case "", anchorStyleGitHub, anchorStyleGitLab, anchorStyleNone:
+		return nil
+	}
+	return fmt.Errorf("unknown anchor style %q", options.AnchorStyle)
+}
+
+// updateTOCContent returns content with a freshly generated table of contents
+// and the table itself. It reports whether the note has toc markers; without
+// them content is returned unchanged unless insert is set.
+func updateTOCContent(content string, options TOCOptions, insert bool) (string, string, bool) {
+	toc := generateTOC(buildOutline(content), options)
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.Split(content, "\n")
+	block := []string{tocStartMarker}
+	if toc != "" {
+		block = append(block, "")
+		block = append(block, strings.Split(toc, "\n")...)
+		block = append(block, "")
+	}
+	block = append(block, tocEndMarker)
+	for i := range block {
+		block[i] += strings.TrimSuffix(newline, "\n")
+	}
+
+	start, end := findTOCMarkers(content)
+	switch {
+	case start >= 0 && end >= 0:
+		lines = append(lines[:start], append(block, lines[end+1:]...)...)
+	case start >= 0:
+		// A lone start marker gets the end marker added
+		lines = append(lines[:start], append(block, lines[start+1:]...)...)
+	case insert:
+		at := tocInsertLine(content)
+		block = append(block, strings.TrimSuffix(newline, "\n"))
+		lines = append(lines[:at], append(block, lines[at:]...)...)
+	default:
+		return content, toc, false
+	}
+
+	return strings.Join(lines, "\n"), toc, true
+}
+
+// generateTOC renders the outline as a markdown list. Headings shallower than
+// MinLevel are left out but their sections still appear; deeper ones than
+// MaxLevel are dropped.
+func generateTOC(outline []*OutlineHeading, options TOCOptions) string {
+	minLevel, maxLevel := options.MinLevel, options.MaxLevel
+	if minLevel == 0 {
+		minLevel = 1
+	}
+	if maxLevel == 0 {
+		maxLevel = 6
+	}
+
+	// Anchors are numbered across the whole note, listed or not, so number
+	// every heading in document order before any are filtered out
+	gitlabAnchors := map[*OutlineHeading]string{}
+	if options.AnchorStyle == anchorStyleGitLab {
+		seen := map[string]bool{}
+		var number func(headings []*OutlineHeading)
+		number = func(headings []*OutlineHeading) {
+			for _, heading := range headings {
+				gitlabAnchors[heading] = uniqueSlug(gitlabSlug(heading.Text), seen)
+				number(heading.Children)
+			}
+		}
+		number(outline)
+	}
+
+	var lines []string
+	// number is shared with the sections of left out headings, which join this list
+	var walk func(headings []*OutlineHeading, depth int, number *int)
+	walk = func(headings []*OutlineHeading, depth int, number *int) {
+		for _, heading := range headings {
+			anchor := heading.Slug
+			if options.AnchorStyle == anchorStyleGitLab {
+				anchor = gitlabAnchors[heading]
+			}
+
+			if heading.Level < minLevel {
+				walk(heading.Children, depth, number)
+				continue
+			}
+			if heading.Level > maxLevel {
+				continue
+			}
+
+			*number++
+			entry := escapeTOCText(heading.Text)
+			if options.AnchorStyle != anchorStyleNone {
+				entry = fmt.Sprintf("[%s](#%s)", entry, anchor)
+			}
+			indent := strings.Repeat("  ", depth)
+			if options.Numbered {
+				indent = strings.Repeat("   ", depth)
+				lines = append(lines, indent+strconv.Itoa(*number)+". "+entry)
+			} else {
+				lines = append(lines, indent+"- "+entry)
+			}
+			walk(heading.Children, depth+1, new(int))
+		}
+	}
+	walk(outline, 0, new(int))
+
+	return strings.Join(lines, "\n")
+}
+
+// escapeTOCText escapes the characters that would break a link label
+func escapeTOCText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+	return replacer.Replace(text)
+}
+
+// gitlabSlug returns the anchor GitLab generates for a heading, which is the
+// GitHub one with runs of hyphens collapsed
+func gitlabSlug(heading string) string {
+	return hyphenRunPattern.ReplaceAllString(githubSlug(strings.TrimSpace(heading)), "-")
+}
+
+// uniqueSlug numbers slug if it was already used, and records it
+func uniqueSlug(slug string, seen map[string]bool) string {
+	unique := slug
+	for i := 1; seen[unique]; i++ {
+		unique = slug + "-" + strconv.Itoa(i)
+	}
+	seen[unique] = true
+	return unique
+}
+
+// findTOCMarkers returns the line indexes of the toc start and end markers,
+// or -1, ignoring any inside front matter or fenced code blocks
+func findTOCMarkers(content string) (int, int) {
+	start, end := -1, -1
+	for i, line := range markdownBodyLines(content) {
+		if line.frontMatter || line.code {
+			continue
+		}
+		marker := strings.TrimSpace(line.text)
+		switch {
+		case start < 0 && strings.EqualFold(marker, tocStartMarker):
+			start = i
+		case start >= 0 && (strings.EqualFold(marker, tocEndMarker) || strings.EqualFold(marker, "<!-- /toc -->")):
+			return start, i
+		}
+	}
+	return start, end
+}
+
+// tocInsertLine returns where a new table of contents goes: after a leading
+// title heading, or at the top of the body
+func tocInsertLine(content string) int {
+	lines := markdownBodyLines(content)
+	first := 0
+	for first < len(lines) && lines[first].frontMatter {
+		first++
+	}
+	for i := first; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i].text)
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "# ") {
+			// Keep a blank line between the title and the markers
+			if i+1 < len(lines) && strings.TrimSpace(lines[i+1].text) == "" {
+				return i + 2
+			}
+			return i + 1
+		}
+		break
+	}
+	return first
+}
+
+// bodyLine is a line of a note with where it sits
+type bodyLine struct {
+	text        string
+	frontMatter bool // part of the front matter block, delimiters included
+	code        bool // inside a fenced code block, fences included
+	fenceStart  bool // opens a fenced code block
+}
+
+// markdownBodyLines splits content into lines, marking front matter and fenced code
+func markdownBodyLines(content string) []bodyLine {
+	texts := strings.Split(content, "\n")
+	lines := make([]bodyLine, len(texts))
+
+	_, body := parseFrontMatter(content)
+	frontMatterLines := strings.Count(content, "\n") - strings.Count(body, "\n")
+
+	var fence string
+	for i, text := range texts {
+		text = strings.TrimSuffix(text, "\r")
+		lines[i].text = text
+		if i < frontMatterLines {
+			lines[i].frontMatter = true
+			continue
+		}
+
+		trimmed := strings.TrimLeft(text, " ")
+		if len(text)-len(trimmed) > 3 && fence == "" {
+			continue
+		}
+		if fence != "" {
+			lines[i].code = true
+			// A closing fence uses the same character, at least as many times, and nothing else
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(strings.TrimSpace(trimmed), fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		for _, char := range []string{"`", "~"} {
+			if strings.HasPrefix(trimmed, char+char+char) {
+				fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, char))]
+				lines[i].code = true
+				lines[i].fenceStart = true
+				break
+			}
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTOC(t *testing.T) {
+	deep := "# Top\n\n#### A\n\n##### Notes\n\n## Notes\n"
+	for _, tc := range []struct {
+		name    string
+		content string
+		options TOCOptions
+		want    string
+	}{
+		{
+			name:    "github numbers repeats",
+			content: "# Intro\n\n## Setup\n\n## Setup\n",
+			want:    "- [Intro](#intro)\n  - [Setup](#setup)\n  - [Setup](#setup-1)",
+		},
+		{
+			name:    "github counts headings deeper than the max",
+			content: deep,
+			options: TOCOptions{MaxLevel: 2},
+			want:    "- [Top](#top)\n  - [Notes](#notes-1)",
+		},
+		{
+			name:    "gitlab counts headings deeper than the max",
+			content: deep,
+			options: TOCOptions{MaxLevel: 2, AnchorStyle: anchorStyleGitLab},
+			want:    "- [Top](#top)\n  - [Notes](#notes-1)",
+		},
+		{
+			name:    "gitlab collapses hyphens",
+			content: "# A - B\n",
+			options: TOCOptions{AnchorStyle: anchorStyleGitLab},
+			want:    "- [A - B](#a-b)",
+		},
+		{
+			name:    "min level lifts sections",
+			content: "# Title\n\n## One\n\n### Deeper\n\n## Two\n",
+			options: TOCOptions{MinLevel: 2, Numbered: true},
+			want:    "1. [One](#one)\n   1. [Deeper](#deeper)\n2. [Two](#two)",
+		},
+		{
+			name:    "plain entries",
+			content: "# [Draft] notes\n",
+			options: TOCOptions{AnchorStyle: anchorStyleNone},
+			want:    `- \[Draft\] notes`,
+		},
+	} {
+		if got := generateTOC(buildOutline(tc.content), tc.options); got != tc.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUpdateTOCContentKeepsCRLF(t *testing.T) {
+	content := strings.ReplaceAll("# Title\n\n<!-- toc -->\n- stale\n<!-- tocstop -->\n\n## Part\n", "\n", "\r\n")
+	want := strings.ReplaceAll("# Title\n\n<!-- toc -->\n\n- [Title](#title)\n  - [Part](#part)\n\n<!-- tocstop -->\n\n## Part\n", "\n", "\r\n")
+
+	got, _, found := updateTOCContent(content, TOCOptions{}, false)
+	if !found || got != want {
+		t.Errorf("found %v, got %q\nwant %q", found, got, want)
+	}
+
+	if _, _, found := updateTOCContent("# Title\n", TOCOptions{}, false); found {
+		t.Error("a note without markers was reported as having them")
+	}
+}
+
+func TestUpdateTOCRefusesUnsavedTab(t *testing.T) {
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	note := filepath.Join(t.TempDir(), "note.md")
+	writeTestFile(t, note, "# Title\n")
+
+	app := NewApp()
+	if _, err := app.OpenTab(note); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTabContent(note, "# Title\n\nUnsaved\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTOC(note, TOCOptions{}); err == nil {
+		t.Fatal("UpdateTOC saved a note with unsaved edits")
+	}
+	if !app.hasUnsavedEdits(note) {
+		t.Error("the tab lost its unsaved edits")
+	}
+}
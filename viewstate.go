@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxViewStates is how many files keep their view state before the least recently used is dropped
+const maxViewStates = 500
+
+// ViewState is where the user left off in a file
+type ViewState struct {
+	CursorOffset   int       `json:"cursorOffset"`
+	ScrollTop      float64   `json:"scrollTop"`
+	FoldedHeadings []string  `json:"foldedHeadings"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// viewStateEntry pairs a file path with its view state on disk
+type viewStateEntry struct {
+	Path  string    `json:"path"`
+	State ViewState `json:"state"`
+}
+
+// viewStateStore is a bounded LRU of view states persisted in the config directory
+type viewStateStore struct {
+	mu       sync.Mutex
+	capacity int
+	loaded   bool
+	entries  []viewStateEntry // most recently used first
+}
+
+// newViewStateStore creates a store that keeps at most capacity entries
+func newViewStateStore(capacity int) *viewStateStore {
+	return &viewStateStore{capacity: capacity}
+}
+
+// getViewStatePath returns the path to the view state file
+func getViewStatePath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "viewstate.json"), nil
+}
+
+// load reads the store from disk once. Callers hold mu.
+func (s *viewStateStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	statePath, err := getViewStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read view state file: %w", err)
+	}
+
+	var entries []viewStateEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse view state file: %w", err)
+	}
+
+	s.entries = entries
+	s.loaded = true
+	return nil
+}
+
+// save writes the store to disk. Callers hold mu.
+func (s *viewStateStore) save() error {
+	statePath, err := getViewStatePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal view state: %w", err)
+	}
+
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write view state file: %w", err)
+	}
+
+	return nil
+}
+
+// indexOf returns the index of the entry for path, or -1. Callers hold mu.
+func (s *viewStateStore) indexOf(path string) int {
+	for i, entry := range s.entries {
+		if entry.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// get returns the view state for path and marks it recently used
+func (s *viewStateStore) get(path string) (*ViewState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	index := s.indexOf(path)
+	if index < 0 {
+		return nil, nil
+	}
+
+	// Move to the front; persisted with the next save
+	entry := s.entries[index]
+	copy(s.entries[1:index+1], s.entries[:index])
+	s.entries[0] = entry
+
+	state := entry.State
+	return &state, nil
+}
+
+// put stores the view state for path, evicting the least recently used entries over capacity
+func (s *viewStateStore) put(path string, state ViewState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	if index := s.indexOf(path); index >= 0 {
+		s.entries = append(s.entries[:index], s.entries[index+1:]...)
+	}
+	s.entries = append([]viewStateEntry{{Path: path, State: state}}, s.entries...)
+
+	if len(s.entries) > s.capacity {
+		s.entries = s.entries[:s.capacity]
+	}
+
+	return s.save()
+}
+
+// removeUnder drops the entries for path and everything inside it
+func (s *viewStateStore) removeUnder(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if !isSameOrChildPath(entry.Path, path) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(s.entries) {
+		return nil
+	}
+
+	s.entries = kept
+	return s.save()
+}
+
+// renameUnder moves the entries for oldPath and everything inside it to newPath
+func (s *viewStateStore) renameUnder(oldPath string, newPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	changed := false
+	for i, entry := range s.entries {
+		if isSameOrChildPath(entry.Path, oldPath) {
+			s.entries[i].Path = newPath + strings.TrimPrefix(entry.Path, oldPath)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return s.save()
+}
+
+// SaveViewState stores the cursor, scroll position and folded headings for a file
+func (a *App) SaveViewState(path string, state ViewState) error {
+	if path == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	state.UpdatedAt = time.Now()
+	return a.viewStates.put(path, state)
+}
+
+// GetViewState returns the saved view state for a file, or nil if there is none
+func (a *App) GetViewState(path string) (*ViewState, error) {
+	return a.viewStates.get(path)
+}
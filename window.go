@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// minWindowWidth and minWindowHeight keep a restored window usable
+	minWindowWidth  = 400
+	minWindowHeight = 300
+
+	// minVisiblePixels is how much of the window must stay on screen
+	minVisiblePixels = 100
+
+	// geometryPollInterval is how often the window is checked for moves and resizes
+	geometryPollInterval = 2 * time.Second
+)
+
+// WindowGeometry describes the size, position and state of the main window.
+// The position is relative to the screen the window is on, as Wails reports
+// it, and can be negative when the window hangs off the left or top edge.
+type WindowGeometry struct {
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	X         int  `json:"x"`
+	Y         int  `json:"y"`
+	Maximized bool `json:"maximized"`
+}
+
+// windowGeometryFromConfig returns the saved window geometry, falling back to defaults
+func windowGeometryFromConfig(config *Config) WindowGeometry {
+	defaults := DefaultConfig()
+	geometry := WindowGeometry{
+		Width:     config.WindowWidth,
+		Height:    config.WindowHeight,
+		X:         config.WindowX,
+		Y:         config.WindowY,
+		Maximized: config.WindowMaximized,
+	}
+
+	if geometry.Width < minWindowWidth {
+		geometry.Width = defaults.WindowWidth
+	}
+	if geometry.Height < minWindowHeight {
+		geometry.Height = defaults.WindowHeight
+	}
+
+	return geometry
+}
+
+// applyWindowOptions loads the saved window geometry into the app options
+func applyWindowOptions(appOptions *options.App) {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Printf("Warning: Could not load config: %v\n", err)
+		config = DefaultConfig()
+	}
+
+	geometry := windowGeometryFromConfig(config)
+	appOptions.Width = geometry.Width
+	appOptions.Height = geometry.Height
+	appOptions.MinWidth = minWindowWidth
+	appOptions.MinHeight = minWindowHeight
+	if geometry.Maximized {
+		appOptions.WindowStartState = options.Maximised
+	}
+}
+
+// clampWindowGeometry shrinks the window to fit the screen and moves it back
+// on screen if less than minVisiblePixels of it would be visible past any edge
+func clampWindowGeometry(geometry WindowGeometry, screenWidth int, screenHeight int) WindowGeometry {
+	if screenWidth <= 0 || screenHeight <= 0 {
+		return geometry
+	}
+
+	if geometry.Width > screenWidth {
+		geometry.Width = screenWidth
+	}
+	if geometry.Height > screenHeight {
+		geometry.Height = screenHeight
+	}
+
+	if geometry.X > screenWidth-minVisiblePixels {
+		geometry.X = screenWidth - geometry.Width
+	}
+	if geometry.X+geometry.Width < minVisiblePixels {
+		geometry.X = 0
+	}
+	if geometry.Y > screenHeight-minVisiblePixels {
+		geometry.Y = screenHeight - geometry.Height
+	}
+	if geometry.Y+geometry.Height < minVisiblePixels {
+		geometry.Y = 0
+	}
+
+	return geometry
+}
+
+// currentScreenSize returns the logical size of the screen holding the window
+func (a *App) currentScreenSize() (int, int) {
+	screens, err := runtime.ScreenGetAll(a.ctx)
+	if err != nil || len(screens) == 0 {
+		return 0, 0
+	}
+
+	// Prefer the screen the window is on, then the primary screen
+	screen := screens[0]
+	for _, s := range screens {
+		if s.IsPrimary {
+			screen = s
+		}
+	}
+	for _, s := range screens {
+		if s.IsCurrent {
+			screen = s
+			break
+		}
+	}
+
+	return screen.Size.Width, screen.Size.Height
+}
+
+// restoreWindowGeometry moves the window to its saved position, clamped to the current screen
+func (a *App) restoreWindowGeometry() {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Printf("Warning: Could not load config: %v\n", err)
+		return
+	}
+
+	geometry := windowGeometryFromConfig(config)
+	if geometry.Maximized {
+		return
+	}
+
+	screenWidth, screenHeight := a.currentScreenSize()
+	clamped := clampWindowGeometry(geometry, screenWidth, screenHeight)
+
+	if clamped.Width != geometry.Width || clamped.Height != geometry.Height {
+		runtime.WindowSetSize(a.ctx, clamped.Width, clamped.Height)
+	}
+	if config.WindowPositionSaved {
+		runtime.WindowSetPosition(a.ctx, clamped.X, clamped.Y)
+	} else {
+		runtime.WindowCenter(a.ctx)
+	}
+}
+
+// GetWindowGeometry returns the current size, position and state of the window
+func (a *App) GetWindowGeometry() WindowGeometry {
+	geometry := WindowGeometry{
+		Maximized: runtime.WindowIsMaximised(a.ctx),
+	}
+	geometry.Width, geometry.Height = runtime.WindowGetSize(a.ctx)
+	geometry.X, geometry.Y = runtime.WindowGetPosition(a.ctx)
+	return geometry
+}
+
+// SaveWindowGeometry persists the current window geometry to config.
+// While maximized only the flag is stored so the normal size is kept for restore.
+func (a *App) SaveWindowGeometry() error {
+	if a.ctx == nil {
+		return nil
+	}
+
+	// A minimised window reports meaningless geometry
+	if runtime.WindowIsMinimised(a.ctx) {
+		return nil
+	}
+
+	return saveWindowGeometry(a.GetWindowGeometry())
+}
+
+// saveWindowGeometry writes the given geometry to config
+func saveWindowGeometry(geometry WindowGeometry) error {
+	return updateConfig(func(config *Config) error {
+		config.WindowMaximized = geometry.Maximized
+		if !geometry.Maximized {
+			config.WindowWidth = geometry.Width
+			config.WindowHeight = geometry.Height
+			config.WindowX = geometry.X
+			config.WindowY = geometry.Y
+			config.WindowPositionSaved = true
+		}
+		return nil
+	})
+}
+
+// watchWindowGeometry saves the window geometry whenever it is moved or resized
+// until ctx is cancelled. Wails v2 has no move/resize events, so it polls.
+func (a *App) watchWindowGeometry(ctx context.Context) {
+	ticker := time.NewTicker(geometryPollInterval)
+	defer ticker.Stop()
+
+	last := a.GetWindowGeometry()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if runtime.WindowIsMinimised(a.ctx) {
+				continue
+			}
+			current := a.GetWindowGeometry()
+			if current == last {
+				continue
+			}
+			last = current
+			if err := saveWindowGeometry(current); err != nil {
+				fmt.Printf("Warning: Could not save window geometry: %v\n", err)
+			}
+		}
+	}
+}
+
+// domReady is called once the frontend has loaded and the window is visible.
+// A reload of the frontend calls it again, so the previous watcher is stopped
+// first rather than left running alongside the new one.
+func (a *App) domReady(ctx context.Context) {
+	if a.stopGeometryWatch != nil {
+		a.stopGeometryWatch()
+	}
+	a.restoreWindowGeometry()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	a.stopGeometryWatch = cancel
+	go a.watchWindowGeometry(watchCtx)
+}
+
+// beforeClose is called when the window is about to close.
+// Returning true would prevent the close.
+func (a *App) beforeClose(ctx context.Context) bool {
+	if a.stopGeometryWatch != nil {
+		a.stopGeometryWatch()
+	}
+
+	if err := a.SaveWindowGeometry(); err != nil {
+		fmt.Printf("Warning: Could not save window geometry: %v\n", err)
+	}
+
+	return false
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Workspace is a named folder of notes that remembers its own session.
+// The active workspace's session lives in the top-level Config fields and is
+// copied back into its entry when switching away.
+type Workspace struct {
+	Name                string       `json:"name"`
+	Path                string       `json:"path"`
+	LastOpenedFile      string       `json:"lastOpenedFile"`
+	LastOpenedDirectory string       `json:"lastOpenedDirectory"`
+	RecentFiles         []RecentFile `json:"recentFiles"`
+	OpenTabs            []string     `json:"openTabs"`
+}
+
+// findWorkspace returns the index of the workspace with name, or -1
+func findWorkspace(config *Config, name string) int {
+	for i, ws := range config.Workspaces {
+		if strings.EqualFold(ws.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// stashActiveWorkspace copies the top-level session into the active workspace
+// entry, or into DefaultSession when no workspace is active
+func stashActiveWorkspace(config *Config) {
+	ws := &config.DefaultSession
+	if config.ActiveWorkspace != "" {
+		index := findWorkspace(config, config.ActiveWorkspace)
+		if index < 0 {
+			return
+		}
+		ws = &config.Workspaces[index]
+	}
+
+	ws.LastOpenedFile = config.LastOpenedFile
+	ws.LastOpenedDirectory = config.LastOpenedDirectory
+	ws.RecentFiles = config.RecentFiles
+	ws.OpenTabs = config.OpenTabs
+}
+
+// ListWorkspaces returns the registered workspaces
+func (a *App) ListWorkspaces() ([]Workspace, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Report the live session for the active workspace
+	stashActiveWorkspace(config)
+
+	return config.Workspaces, nil
+}
+
+// GetActiveWorkspace returns the active workspace, or nil if none is active
+func (a *App) GetActiveWorkspace() (*Workspace, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	index := findWorkspace(config, config.ActiveWorkspace)
+	if index < 0 {
+		return nil, nil
+	}
+
+	stashActiveWorkspace(config)
+	ws := config.Workspaces[index]
+	return &ws, nil
+}
+
+// AddWorkspace registers a folder as a workspace. The name defaults to the folder name.
+func (a *App) AddWorkspace(name string, path string) (Workspace, error) {
+	if path == "" {
+		return Workspace{}, fmt.Errorf("workspace path cannot be empty")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Workspace{}, fmt.Errorf("failed to resolve workspace path %s: %w", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Workspace{}, fmt.Errorf("failed to get file info for %s: %w", absPath, err)
+	}
+	if !info.IsDir() {
+		return Workspace{}, fmt.Errorf("path %s is not a directory", absPath)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = filepath.Base(absPath)
+	}
+
+	ws := Workspace{
+		Name:                name,
+		Path:                absPath,
+		LastOpenedDirectory: absPath,
+		RecentFiles:         []RecentFile{},
+		OpenTabs:            []string{},
+	}
+	err = updateConfig(func(config *Config) error {
+		if findWorkspace(config, name) >= 0 {
+			return fmt.Errorf("a workspace named %s already exists", name)
+		}
+		for _, existing := range config.Workspaces {
+			if existing.Path == absPath {
+				return fmt.Errorf("%s is already registered as workspace %s", absPath, existing.Name)
+			}
+		}
+
+		config.Workspaces = append(config.Workspaces, ws)
+		return nil
+	})
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	return ws, nil
+}
+
+// RemoveWorkspace unregisters a workspace. Its files are left untouched.
+// Removing the active workspace returns to the session from before the first
+// workspace was opened.
+func (a *App) RemoveWorkspace(name string) error {
+	var config *Config
+	err := updateConfig(func(c *Config) error {
+		index := findWorkspace(c, name)
+		if index < 0 {
+			return fmt.Errorf("no workspace named %s", name)
+		}
+
+		if strings.EqualFold(c.ActiveWorkspace, name) {
+			loadSession(c, c.DefaultSession)
+			config = c
+		}
+		c.Workspaces = append(c.Workspaces[:index], c.Workspaces[index+1:]...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if config != nil {
+		a.workspaceName = ""
+		a.workspacePath = ""
+		a.currentDir = config.LastOpenedDirectory
+		a.restoreTabs(config)
+		a.restartBackupScheduler()
+	}
+
+	a.UpdateWindowTitleWithCurrentDir()
+	return nil
+}
+
+// SwitchWorkspace saves the current session into the active workspace and
+// restores the directory, open tabs and recent files of the named one. An
+// empty name leaves workspaces and restores the session from before the first
+// one was opened. Switching to the active session keeps it as it is.
+func (a *App) SwitchWorkspace(name string) (CurrentFilesState, error) {
+	var config *Config
+	var target Workspace
+	err := updateConfig(func(c *Config) error {
+		// Stash first so switching to the active session reads it back as it is now
+		stashActiveWorkspace(c)
+
+		if name == "" {
+			target = c.DefaultSession
+		} else {
+			index := findWorkspace(c, name)
+			if index < 0 {
+				return fmt.Errorf("no workspace named %s", name)
+			}
+			target = c.Workspaces[index]
+			if _, err := os.Stat(target.Path); err != nil {
+				return fmt.Errorf("workspace folder %s is not available: %w", target.Path, err)
+			}
+		}
+
+		loadSession(c, target)
+		config = c
+		return nil
+	})
+	if err != nil {
+		return CurrentFilesState{}, err
+	}
+
+	a.workspaceName = target.Name
+	a.workspacePath = target.Path
+	a.currentDir = config.LastOpenedDirectory
+	a.restoreTabs(config)
+	a.restartBackupScheduler()
+	a.UpdateWindowTitleWithCurrentDir()
+
+	return a.GetCurrentFilesState(), nil
+}
+
+// loadSession makes target, a workspace or the default session, the active
+// session in the top-level config fields
+func loadSession(c *Config, target Workspace) {
+	c.ActiveWorkspace = target.Name
+	c.LastOpenedFile = target.LastOpenedFile
+	c.LastOpenedDirectory = target.LastOpenedDirectory
+	c.RecentFiles = target.RecentFiles
+	c.OpenTabs = target.OpenTabs
+	if c.RecentFiles == nil {
+		c.RecentFiles = []RecentFile{}
+	}
+	if c.OpenTabs == nil {
+		c.OpenTabs = []string{}
+	}
+
+	// Fall back to the workspace root, or the home folder outside a
+	// workspace, if the remembered folder is gone
+	fallback := target.Path
+	if fallback == "" {
+		fallback = homeDirOrRoot()
+	} else if c.LastOpenedDirectory == "" || !isSameOrChildPath(c.LastOpenedDirectory, target.Path) {
+		c.LastOpenedDirectory = fallback
+	}
+	if _, err := os.Stat(c.LastOpenedDirectory); err != nil {
+		c.LastOpenedDirectory = fallback
+	}
+}
+
+// loadActiveWorkspace sets the active workspace from config at startup
+func (a *App) loadActiveWorkspace(config *Config) {
+	index := findWorkspace(config, config.ActiveWorkspace)
+	if index < 0 {
+		return
+	}
+
+	a.workspaceName = config.Workspaces[index].Name
+	a.workspacePath = config.Workspaces[index].Path
+}
+
+// resolveInWorkspace returns p cleaned, resolving a relative path against root
+func resolveInWorkspace(root string, p string) string {
+	p = filepath.Clean(p)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	return p
+}
+
+// workspaceRoot returns the active workspace folder, or the current directory
+// when no workspace is active
+func (a *App) workspaceRoot() string {
+	if a.workspacePath != "" {
+		return a.workspacePath
+	}
+	return a.currentDir
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// setupWorkspaces writes a config with one workspace, work, whose stored
+// session is stale, and a stale default session. The live session has note
+// open. active chooses whether work is the active workspace.
+func setupWorkspaces(t *testing.T, active bool) (*App, string) {
+	t.Helper()
+	t.Setenv("MARKDOWNS_HOME", t.TempDir())
+	root := t.TempDir()
+	note := filepath.Join(root, "note.md")
+	writeTestFile(t, note, "# Note\n")
+
+	err := updateConfig(func(c *Config) error {
+		c.Workspaces = []Workspace{{Name: "work", Path: root, LastOpenedDirectory: root}}
+		c.DefaultSession = Workspace{LastOpenedDirectory: root}
+		c.LastOpenedDirectory = root
+		c.LastOpenedFile = note
+		c.OpenTabs = []string{note}
+		c.RecentFiles = []RecentFile{{Path: note}}
+		if active {
+			c.ActiveWorkspace = "work"
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	if active {
+		app.workspaceName = "work"
+		app.workspacePath = root
+	}
+	app.currentDir = root
+	return app, note
+}
+
+// checkLiveSession checks that note is still the open tab and recent file
+func checkLiveSession(t *testing.T, app *App, note string) {
+	t.Helper()
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.OpenTabs, []string{note}) || len(config.RecentFiles) != 1 || config.LastOpenedFile != note {
+		t.Errorf("session = tabs %q, recent %+v, file %q; want the live session kept", config.OpenTabs, config.RecentFiles, config.LastOpenedFile)
+	}
+	if len(app.tabs) != 1 || app.tabs[0].Path != note {
+		t.Errorf("open tabs = %+v, want only %s", app.tabs, note)
+	}
+}
+
+func TestSwitchToActiveSessionKeepsIt(t *testing.T) {
+	app, note := setupWorkspaces(t, true)
+	if _, err := app.SwitchWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+	checkLiveSession(t, app, note)
+
+	app, note = setupWorkspaces(t, false)
+	if _, err := app.SwitchWorkspace(""); err != nil {
+		t.Fatal(err)
+	}
+	checkLiveSession(t, app, note)
+}
+
+func TestRemoveActiveWorkspaceRestoresDefaultSession(t *testing.T) {
+	app, note := setupWorkspaces(t, false)
+
+	// Entering the workspace stashes the live session as the default one
+	if _, err := app.SwitchWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RemoveWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+	if app.workspacePath != "" {
+		t.Errorf("workspace path = %q after removing the active workspace", app.workspacePath)
+	}
+	checkLiveSession(t, app, note)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ActiveWorkspace != "" || len(config.Workspaces) != 0 {
+		t.Errorf("active = %q, workspaces = %+v, want none", config.ActiveWorkspace, config.Workspaces)
+	}
+}